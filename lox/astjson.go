@@ -0,0 +1,160 @@
+package lox
+
+import "encoding/json"
+
+// MarshalAST encodes statements as indented JSON. Each node is an object
+// whose "type" names its kind, with its children under the names the syntax
+// tree uses and a "line" where the node has a token.
+func MarshalAST(stmts []Stmt) ([]byte, error) {
+	return json.MarshalIndent(astStmts(stmts), "", "  ")
+}
+
+type astNode map[string]interface{}
+
+// astJSON converts syntax trees to astNodes for encoding.
+type astJSON struct{}
+
+func astStmt(stmt Stmt) interface{} {
+	node, _ := stmt.Accept(astJSON{})
+	return node
+}
+
+func astStmts(stmts []Stmt) []interface{} {
+	nodes := make([]interface{}, len(stmts))
+	for k, stmt := range stmts {
+		nodes[k] = astStmt(stmt)
+	}
+	return nodes
+}
+
+func astExpr(expr Expr) interface{} {
+	if expr == nil {
+		return nil
+	}
+	node, _ := expr.Accept(astJSON{})
+	return node
+}
+
+func (astJSON) VisitAssignExpr(expr Assign) (interface{}, error) {
+	return astNode{"type": "Assign", "line": expr.name.line, "name": expr.name.lexeme, "value": astExpr(expr.value)}, nil
+}
+
+func (astJSON) VisitBinaryExpr(expr Binary) (interface{}, error) {
+	return astNode{
+		"type":     "Binary",
+		"line":     expr.operator.line,
+		"operator": expr.operator.lexeme,
+		"left":     astExpr(expr.left),
+		"right":    astExpr(expr.right),
+	}, nil
+}
+
+func (astJSON) VisitCallExpr(expr Call) (interface{}, error) {
+	arguments := make([]interface{}, len(expr.arguments))
+	for k, argument := range expr.arguments {
+		arguments[k] = astExpr(argument)
+	}
+	return astNode{"type": "Call", "line": expr.paren.line, "callee": astExpr(expr.callee), "arguments": arguments}, nil
+}
+
+func (astJSON) VisitGetExpr(expr Get) (interface{}, error) {
+	return astNode{"type": "Get", "line": expr.name.line, "object": astExpr(expr.object), "name": expr.name.lexeme}, nil
+}
+
+func (astJSON) VisitGroupingExpr(expr Grouping) (interface{}, error) {
+	return astNode{"type": "Grouping", "expression": astExpr(expr.expression)}, nil
+}
+
+func (astJSON) VisitLiteralExpr(expr Literal) (interface{}, error) {
+	return astNode{"type": "Literal", "value": expr.value}, nil
+}
+
+func (astJSON) VisitLogicalExpr(expr Logical) (interface{}, error) {
+	return astNode{
+		"type":     "Logical",
+		"line":     expr.operator.line,
+		"operator": expr.operator.lexeme,
+		"left":     astExpr(expr.left),
+		"right":    astExpr(expr.right),
+	}, nil
+}
+
+func (astJSON) VisitSetExpr(expr Set) (interface{}, error) {
+	return astNode{
+		"type":   "Set",
+		"line":   expr.name.line,
+		"object": astExpr(expr.object),
+		"name":   expr.name.lexeme,
+		"value":  astExpr(expr.value),
+	}, nil
+}
+
+func (astJSON) VisitUnaryExpr(expr Unary) (interface{}, error) {
+	return astNode{"type": "Unary", "line": expr.operator.line, "operator": expr.operator.lexeme, "right": astExpr(expr.right)}, nil
+}
+
+func (astJSON) VisitVariableExpr(expr Variable) (interface{}, error) {
+	return astNode{"type": "Variable", "line": expr.name.line, "name": expr.name.lexeme}, nil
+}
+
+func (astJSON) VisitBlockStmt(stmt Block) (interface{}, error) {
+	return astNode{"type": "Block", "statements": astStmts(stmt.statements)}, nil
+}
+
+func (astJSON) VisitExpressionStmt(stmt Expression) (interface{}, error) {
+	return astNode{"type": "Expression", "expression": astExpr(stmt.expression)}, nil
+}
+
+func (astJSON) VisitFunctionStmt(stmt Function) (interface{}, error) {
+	params := make([]string, len(stmt.params))
+	for k, param := range stmt.params {
+		params[k] = param.lexeme
+	}
+	return astNode{
+		"type":   "Function",
+		"line":   stmt.name.line,
+		"name":   stmt.name.lexeme,
+		"params": params,
+		"body":   astStmts(stmt.body),
+	}, nil
+}
+
+func (astJSON) VisitIfStmt(stmt If) (interface{}, error) {
+	node := astNode{"type": "If", "condition": astExpr(stmt.condition), "then": astStmt(*stmt.thenBranch)}
+	if stmt.elseBranch != nil {
+		node["else"] = astStmt(*stmt.elseBranch)
+	}
+	return node, nil
+}
+
+func (astJSON) VisitEchoStmt(stmt Echo) (interface{}, error) {
+	return astNode{"type": "Echo", "expression": astExpr(stmt.expression)}, nil
+}
+
+func (astJSON) VisitImportStmt(stmt Import) (interface{}, error) {
+	return astNode{"type": "Import", "line": stmt.keyword.line, "path": stmt.path.literal}, nil
+}
+
+func (astJSON) VisitPrintStmt(stmt Print) (interface{}, error) {
+	return astNode{"type": "Print", "expression": astExpr(stmt.expression)}, nil
+}
+
+func (astJSON) VisitReturnStmt(stmt Return) (interface{}, error) {
+	node := astNode{"type": "Return", "line": stmt.keyword.line}
+	if stmt.value != nil {
+		node["value"] = astExpr(*stmt.value)
+	}
+	return node, nil
+}
+
+func (astJSON) VisitVarStmt(stmt Var) (interface{}, error) {
+	node := astNode{"type": "Var", "line": stmt.name.line, "name": stmt.name.lexeme}
+	if stmt.initializer != nil {
+		node["initializer"] = astExpr(*stmt.initializer)
+	}
+	return node, nil
+}
+
+func (astJSON) VisitWhileStmt(stmt While) (interface{}, error) {
+	return astNode{"type": "While", "condition": astExpr(stmt.condition), "body": astStmt(stmt.body)}, nil
+}
@@ -0,0 +1,201 @@
+package lox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AstPrinter formats syntax trees as S-expressions.
+type AstPrinter struct {
+	// Indent, if set, puts the statements inside blocks, functions, ifs and
+	// whiles on lines of their own, indented by this much for each level.
+	Indent string
+}
+
+func (p AstPrinter) Print(expr Expr) (string, error) {
+	return p.print(expr)
+}
+
+func (p AstPrinter) VisitBinaryExpr(expr Binary) (interface{}, error) {
+	return p.parenthesize(expr.operator.lexeme, expr.left, expr.right)
+}
+
+func (p AstPrinter) VisitGroupingExpr(expr Grouping) (interface{}, error) {
+	return p.parenthesize("group", expr.expression)
+}
+
+func (p AstPrinter) VisitLiteralExpr(expr Literal) (interface{}, error) {
+	switch value := expr.value.(type) {
+	case nil:
+		return "nil", nil
+	case string:
+		// Quoted, so that strings can't be mistaken for names.
+		return fmt.Sprintf("%q", value), nil
+	}
+	return fmt.Sprintf("%v", expr.value), nil
+}
+
+func (p AstPrinter) VisitUnaryExpr(expr Unary) (interface{}, error) {
+	return p.parenthesize(expr.operator.lexeme, expr.right)
+}
+
+func (p AstPrinter) VisitVariableExpr(expr Variable) (interface{}, error) {
+	return expr.name.lexeme, nil
+}
+
+func (p AstPrinter) VisitAssignExpr(expr Assign) (interface{}, error) {
+	return p.parenthesize("=", expr.name.lexeme, expr.value)
+}
+
+func (p AstPrinter) VisitLogicalExpr(expr Logical) (interface{}, error) {
+	return p.parenthesize(expr.operator.lexeme, expr.left, expr.right)
+}
+
+func (p AstPrinter) VisitCallExpr(expr Call) (interface{}, error) {
+	parts := []interface{}{expr.callee}
+	for _, argument := range expr.arguments {
+		parts = append(parts, argument)
+	}
+	return p.parenthesize("call", parts...)
+}
+
+func (p AstPrinter) VisitGetExpr(expr Get) (interface{}, error) {
+	return p.parenthesize(".", expr.object, expr.name.lexeme)
+}
+
+func (p AstPrinter) VisitSetExpr(expr Set) (interface{}, error) {
+	return p.parenthesize("=", Get{expr.object, expr.name}, expr.value)
+}
+
+// PrintStmt formats stmt, and any statements it contains, on one line.
+func (p AstPrinter) PrintStmt(stmt Stmt) (string, error) {
+	return p.print(stmt)
+}
+
+func (p AstPrinter) VisitBlockStmt(stmt Block) (interface{}, error) {
+	return p.nest("block", nil, stmt.statements)
+}
+
+func (p AstPrinter) VisitExpressionStmt(stmt Expression) (interface{}, error) {
+	return p.parenthesize(";", stmt.expression)
+}
+
+func (p AstPrinter) VisitFunctionStmt(stmt Function) (interface{}, error) {
+	params := make([]string, len(stmt.params))
+	for k, param := range stmt.params {
+		params[k] = param.lexeme
+	}
+
+	heads := []interface{}{stmt.name.lexeme, "(" + strings.Join(params, " ") + ")"}
+	return p.nest("fun", heads, stmt.body)
+}
+
+func (p AstPrinter) VisitIfStmt(stmt If) (interface{}, error) {
+	branches := []Stmt{*stmt.thenBranch}
+	if stmt.elseBranch != nil {
+		branches = append(branches, *stmt.elseBranch)
+	}
+	return p.nest("if", []interface{}{stmt.condition}, branches)
+}
+
+func (p AstPrinter) VisitEchoStmt(stmt Echo) (interface{}, error) {
+	return p.parenthesize("echo", stmt.expression)
+}
+
+func (p AstPrinter) VisitImportStmt(stmt Import) (interface{}, error) {
+	return p.parenthesize("import", stmt.path.lexeme)
+}
+
+func (p AstPrinter) VisitPrintStmt(stmt Print) (interface{}, error) {
+	return p.parenthesize("print", stmt.expression)
+}
+
+func (p AstPrinter) VisitReturnStmt(stmt Return) (interface{}, error) {
+	if stmt.value == nil {
+		return "(return)", nil
+	}
+	return p.parenthesize("return", *stmt.value)
+}
+
+func (p AstPrinter) VisitVarStmt(stmt Var) (interface{}, error) {
+	if stmt.initializer == nil {
+		return p.parenthesize("var", stmt.name.lexeme)
+	}
+	return p.parenthesize("var", stmt.name.lexeme, *stmt.initializer)
+}
+
+func (p AstPrinter) VisitWhileStmt(stmt While) (interface{}, error) {
+	return p.nest("while", []interface{}{stmt.condition}, []Stmt{stmt.body})
+}
+
+// parenthesize formats an S-expression. Parts are expressions, statements or
+// strings to be written as they are.
+func (p AstPrinter) parenthesize(name string, parts ...interface{}) (string, error) {
+	w := &strings.Builder{}
+
+	w.WriteString("(" + name)
+	for _, part := range parts {
+		w.WriteString(" ")
+
+		s, err := p.print(part)
+		if err != nil {
+			return "", err
+		}
+		w.WriteString(s)
+	}
+	w.WriteString(")")
+
+	return w.String(), nil
+}
+
+// nest formats a statement that contains others, putting each of them on
+// its own line if there's an Indent.
+func (p AstPrinter) nest(name string, heads []interface{}, body []Stmt) (string, error) {
+	if p.Indent == "" {
+		parts := heads
+		for _, stmt := range body {
+			parts = append(parts, stmt)
+		}
+		return p.parenthesize(name, parts...)
+	}
+
+	w := &strings.Builder{}
+	head, err := p.parenthesize(name, heads...)
+	if err != nil {
+		return "", err
+	}
+	w.WriteString(strings.TrimSuffix(head, ")"))
+
+	for _, stmt := range body {
+		s, err := p.print(stmt)
+		if err != nil {
+			return "", err
+		}
+		w.WriteString("\n" + p.Indent + strings.ReplaceAll(s, "\n", "\n"+p.Indent))
+	}
+	w.WriteString(")")
+
+	return w.String(), nil
+}
+
+func (p AstPrinter) print(part interface{}) (string, error) {
+	var ret interface{}
+	var err error
+	switch part := part.(type) {
+	case string:
+		return part, nil
+	case Expr:
+		ret, err = part.Accept(p)
+	case Stmt:
+		ret, err = part.Accept(p)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	s, ok := ret.(string)
+	if !ok {
+		return "", fmt.Errorf("not a string: %v", ret)
+	}
+	return s, nil
+}
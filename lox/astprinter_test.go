@@ -0,0 +1,132 @@
+package lox
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestAstPrinter(t *testing.T) {
+	expression := Binary{
+		Unary{
+			NewToken(MINUS, "-", nil, 1),
+			Literal{123.0},
+		},
+		NewToken(STAR, "*", nil, 1),
+		Grouping{
+			Literal{45.67},
+		},
+	}
+
+	p := AstPrinter{}
+
+	got, err := p.Print(expression)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := "(* (- 123) (group 45.67))"
+
+	if want != got {
+		t.Errorf("want %v got %v", want, got)
+	}
+}
+
+func TestAstPrinterStmt(t *testing.T) {
+	tokens, err := Scan(`
+var a = 1;
+fun add(x, y) { return x + y; }
+if (a > 0 and true) print add(a, 2); else a = nil;
+print "a b" + a;
+while (a < 3) { o.n = a; a = a + 1; }
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts, err := Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"(var a 1)",
+		"(fun add (x y) (return (+ x y)))",
+		"(if (and (> a 0) true) (print (call add a 2)) (; (= a nil)))",
+		`(print (+ "a b" a))`,
+		"(while (< a 3) (block (; (= (. o n) a)) (; (= a (+ a 1)))))",
+	}
+	for k, stmt := range stmts {
+		got, err := AstPrinter{}.PrintStmt(stmt)
+		if err != nil {
+			t.Fatalf("want no error, got %v", err)
+		}
+		if got != want[k] {
+			t.Errorf("want %v got %v", want[k], got)
+		}
+	}
+}
+
+func TestAstPrinterIndent(t *testing.T) {
+	tokens, _ := Scan(`
+fun count(n) {
+    if (n > 1) count(n - 1); else { print n; }
+    return;
+}
+`)
+	stmts, err := Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := AstPrinter{Indent: "  "}.PrintStmt(stmts[0])
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	want := `(fun count (n)
+  (if (> n 1)
+    (; (call count (- n 1)))
+    (block
+      (print n)))
+  (return))`
+	if got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestMarshalAST(t *testing.T) {
+	tokens, _ := Scan("var a = -1;\nif (a) print a;")
+	stmts, err := Parse(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := MarshalAST(stmts)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	var got []map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("want valid JSON, got %v", err)
+	}
+	want := []map[string]interface{}{
+		{
+			"type": "Var", "line": 1.0, "name": "a",
+			"initializer": map[string]interface{}{
+				"type": "Unary", "line": 1.0, "operator": "-",
+				"right": map[string]interface{}{"type": "Literal", "value": 1.0},
+			},
+		},
+		{
+			"type":      "If",
+			"condition": map[string]interface{}{"type": "Variable", "line": 2.0, "name": "a"},
+			"then": map[string]interface{}{
+				"type":       "Print",
+				"expression": map[string]interface{}{"type": "Variable", "line": 2.0, "name": "a"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
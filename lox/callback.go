@@ -0,0 +1,93 @@
+package lox
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Callback is a handle that lets Go code call a function defined by a script,
+// such as an event handler the script registered.
+type Callback struct {
+	interpreter *Interpreter
+	function    LoxCallable
+	name        Token
+}
+
+// Global returns the value of the global variable name, and whether it is
+// defined.
+func (i *Interpreter) Global(name string) (interface{}, bool) {
+	value, ok := i.globals.values[name]
+	return value, ok
+}
+
+// Globals returns the names of the global variables in sorted order.
+func (i *Interpreter) Globals() []string {
+	names := make([]string, 0, len(i.globals.values))
+	for name := range i.globals.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Callback looks up the global variable name and returns a handle for
+// calling it. It fails if name is undefined or isn't callable.
+func (i *Interpreter) Callback(name string) (*Callback, error) {
+	value, ok := i.Global(name)
+	if !ok {
+		return nil, fmt.Errorf("undefined variable %q", name)
+	}
+
+	return i.NewCallback(value)
+}
+
+// NewCallback returns a handle for calling value, which must be a Lox
+// function or native, for example one a script passed to a native.
+func (i *Interpreter) NewCallback(value interface{}) (*Callback, error) {
+	function, ok := value.(LoxCallable)
+	if !ok {
+		return nil, fmt.Errorf("%v is not callable", value)
+	}
+
+	name := NewToken(IDENTIFIER, fmt.Sprint(function), nil, 0)
+	return &Callback{i, function, name}, nil
+}
+
+// Call calls the function with arguments converted from Go values the same
+// way native results are, and returns its result. A call made while no script
+// is running has fresh step and allocation budgets; one made by a native
+// counts against the run that called the native. Runtime errors, including
+// those from an exhausted budget, are returned as errors.
+func (c *Callback) Call(arguments ...interface{}) (interface{}, error) {
+	return c.CallContext(c.interpreter.ctx, arguments...)
+}
+
+// CallContext is like Call but gives up when ctx is done.
+func (c *Callback) CallContext(ctx context.Context, arguments ...interface{}) (interface{}, error) {
+	converted := make([]interface{}, len(arguments))
+	for k, argument := range arguments {
+		if argument == nil {
+			continue
+		}
+
+		value, err := c.interpreter.fromGo(reflect.ValueOf(argument))
+		if err != nil {
+			return nil, fmt.Errorf("argument %d to %v: %w", k+1, c.function, err)
+		}
+		converted[k] = value
+	}
+
+	i := c.interpreter
+	if len(i.frames) == 0 {
+		defer i.start(ctx)()
+	} else {
+		previous := i.ctx
+		i.ctx = ctx
+		defer func() {
+			i.ctx = previous
+		}()
+	}
+	return i.call(c.function, c.name, converted)
+}
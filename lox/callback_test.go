@@ -0,0 +1,98 @@
+package lox
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCallback(t *testing.T) {
+	interpreter := NewInterpreter()
+	source := `
+var greeting = "hello";
+fun greet(name, times) {
+    var s = "";
+    while (times > 0) {
+        s = s + greeting + " " + name + ";";
+        times = times - 1;
+    }
+    return s;
+}
+fun spin() {
+    while (true) {}
+}
+`
+	if err := interpret(interpreter, source); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	greet, err := interpreter.Callback("greet")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	got, err := greet.Call("bob", 2)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if want := "hello bob;hello bob;"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+
+	if _, err := greet.Call("bob"); err == nil {
+		t.Error("want arity error, got none")
+	}
+
+	if _, err := interpreter.Callback("greeting"); err == nil {
+		t.Error("want error for a non-callable global, got none")
+	}
+	if _, err := interpreter.Callback("missing"); err == nil {
+		t.Error("want error for an undefined global, got none")
+	}
+
+	spin, _ := interpreter.Callback("spin")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := spin.CallContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("want error %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestCallbackBudget(t *testing.T) {
+	interpreter := NewInterpreter()
+	if err := interpret(interpreter, "fun add(a, b) { return a + b; }"); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	interpreter.SetMaxSteps(50)
+
+	add, _ := interpreter.Callback("add")
+	for k := 0; k < 100; k++ {
+		if _, err := add.Call(k, 1); err != nil {
+			t.Fatalf("call %d: want no error, got %v", k+1, err)
+		}
+	}
+}
+
+func TestCallbackBudgetFromNative(t *testing.T) {
+	interpreter := NewInterpreter()
+	interpreter.DefineNative("repeat", func(n int, body interface{}) error {
+		callback, err := interpreter.NewCallback(body)
+		if err != nil {
+			return err
+		}
+		for k := 0; k < n; k++ {
+			if _, err := callback.Call(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	interpreter.SetMaxSteps(1000)
+
+	// The native reports the error as its own, so only the message is left.
+	err := interpret(interpreter, "fun body() { var a = 1; var b = 2; } repeat(1000, body);")
+	if err == nil || !strings.Contains(err.Error(), ErrStepLimitExceeded.Error()) {
+		t.Errorf("want error %v, got %v", ErrStepLimitExceeded, err)
+	}
+}
@@ -0,0 +1,65 @@
+package lox
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentInterpreters runs many interpreters at once and checks that
+// each produces the same output as a lone run. Run it with -race to check
+// that interpreters share no state.
+func TestConcurrentInterpreters(t *testing.T) {
+	files, err := filepath.Glob("../examples/*.lox")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("want example scripts, got %v (%v)", files, err)
+	}
+
+	sources := make(map[string]string)
+	want := make(map[string]string)
+	for _, file := range files {
+		bytes, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources[file] = string(bytes)
+
+		out, err := runIsolated(sources[file])
+		if err != nil {
+			t.Fatalf("%s: want no error, got %v", file, err)
+		}
+		want[file] = out
+	}
+
+	const copies = 8
+
+	var wg sync.WaitGroup
+	for _, file := range files {
+		for n := 0; n < copies; n++ {
+			wg.Add(1)
+			go func(file string) {
+				defer wg.Done()
+
+				got, err := runIsolated(sources[file])
+				if err != nil {
+					t.Errorf("%s: want no error, got %v", file, err)
+				}
+				if got != want[file] {
+					t.Errorf("%s: want output %q, got %q", file, want[file], got)
+				}
+			}(file)
+		}
+	}
+	wg.Wait()
+}
+
+func runIsolated(source string) (string, error) {
+	stdout := &bytes.Buffer{}
+	interpreter := NewInterpreter()
+	interpreter.SetStdout(stdout)
+
+	err := interpret(interpreter, source)
+	return stdout.String(), err
+}
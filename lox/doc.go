@@ -0,0 +1,25 @@
+// Package lox is a tree-walking interpreter for the Lox language from
+// Crafting Interpreters.
+//
+// A program goes through the same stages the glox command uses:
+//
+//	tokens, err := lox.Scan(source)
+//	stmts, err := lox.Parse(tokens)
+//	interpreter := lox.NewInterpreter()
+//	err = lox.Resolve(interpreter, stmts)
+//	err = interpreter.Interpret(stmts)
+//
+// A script that runs many times can instead be compiled once with Compile and
+// the resulting Program run by any number of interpreters with Run.
+//
+// Go values can be handed to scripts with Interpreter.Define, and Go functions
+// with Interpreter.DefineNative. Lox numbers are float64, strings are string,
+// booleans are bool and nil is nil.
+//
+// Interpreters share no state, so separate interpreters can run scripts in
+// parallel goroutines. A single Interpreter must not be used concurrently.
+// Give each one its own streams with SetStdout and SetStdin; by default they
+// all use the process's standard streams.
+package lox
+
+//go:generate go run ../tool/generate.go .
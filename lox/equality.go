@@ -0,0 +1,95 @@
+package lox
+
+import (
+	"hash/fnv"
+	"math"
+	"reflect"
+)
+
+// isEqual implements Lox's == operator. Values of different types are never
+// equal. Numbers follow IEEE 754, so NaN is not equal to itself and 0 equals
+// -0. Strings compare by content, while functions and other objects compare
+// by identity.
+func isEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		b, ok := b.(bool)
+		return ok && a == b
+	case float64:
+		b, ok := b.(float64)
+		return ok && a == b
+	case string:
+		b, ok := b.(string)
+		return ok && a == b
+	case *LoxFunction:
+		b, ok := b.(*LoxFunction)
+		return ok && a == b
+	case *HostObject:
+		b, ok := b.(*HostObject)
+		if !ok || a.value.Type() != b.value.Type() {
+			return false
+		}
+		// Wrapping the same Go pointer twice gives the same object.
+		if a.value.Kind() == reflect.Ptr {
+			return a.value.Pointer() == b.value.Pointer()
+		}
+		return goEqual(a.value.Interface(), b.value.Interface())
+	}
+
+	return goEqual(a, b)
+}
+
+// goEqual compares natives and other host values. Only comparable values of
+// the same type can be equal. A comparable type can still hold an
+// uncomparable value in an interface field, so a panic counts as unequal.
+func goEqual(a, b interface{}) (equal bool) {
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb || !ta.Comparable() {
+		return false
+	}
+
+	defer func() {
+		if recover() != nil {
+			equal = false
+		}
+	}()
+	return a == b
+}
+
+// hashValue returns a hash consistent with isEqual: equal values always hash
+// the same. It reports false for values that cannot be used as keys. A Lox
+// map or set should bucket entries by hashValue and compare them with isEqual.
+func hashValue(value interface{}) (uint64, bool) {
+	switch v := value.(type) {
+	case nil:
+		return 0, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 2, true
+	case float64:
+		if v == 0 {
+			// 0 and -0 are equal, so they must hash the same.
+			v = 0
+		}
+		return math.Float64bits(v), true
+	case string:
+		h := fnv.New64a()
+		h.Write([]byte(v))
+		return h.Sum64(), true
+	case *LoxFunction:
+		return uint64(reflect.ValueOf(v).Pointer()), true
+	case *HostObject:
+		return hashValue(v.value.Interface())
+	}
+
+	if t := reflect.TypeOf(value); t.Comparable() {
+		h := fnv.New64a()
+		h.Write([]byte(t.String()))
+		return h.Sum64(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,75 @@
+package lox
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestIsEqual(t *testing.T) {
+	nan := math.NaN()
+	f := NewLoxFunction(Function{}, nil, nil)
+	g := NewLoxFunction(Function{}, nil, nil)
+	clock, _ := newNative("clock", reflect.ValueOf(clock))
+
+	// A comparable struct that holds an uncomparable value.
+	type holder struct{ V interface{} }
+	interpreter := NewInterpreter()
+	config := &hostConfig{Name: "glox"}
+
+	cases := []struct {
+		name string
+		a, b interface{}
+		want bool
+	}{
+		{"nil", nil, nil, true},
+		{"nil and false", nil, false, false},
+		{"numbers", 1.0, 1.0, true},
+		{"zeros", 0.0, math.Copysign(0, -1), true},
+		{"NaN", nan, nan, false},
+		{"number and string", 1.0, "1", false},
+		{"strings", "abc", "abc", true},
+		{"same function", f, f, true},
+		{"identical functions", f, g, false},
+		{"same native", clock, clock, true},
+		{"same host pointer", interpreter.NewHostObject(config), interpreter.NewHostObject(config), true},
+		{"different host pointers", interpreter.NewHostObject(config), interpreter.NewHostObject(&hostConfig{Name: "glox"}), false},
+		{"equal host values", interpreter.NewHostObject(holder{1.0}), interpreter.NewHostObject(holder{1.0}), true},
+		{"uncomparable host values", interpreter.NewHostObject(holder{[]int{1}}), interpreter.NewHostObject(holder{[]int{1}}), false},
+		{"uncomparable Go values", holder{[]int{1}}, holder{[]int{1}}, false},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.name, func(t *testing.T) {
+			if got := isEqual(cc.a, cc.b); got != cc.want {
+				t.Errorf("want %v, got %v", cc.want, got)
+			}
+		})
+	}
+}
+
+func TestHashValue(t *testing.T) {
+	clock, _ := newNative("clock", reflect.ValueOf(clock))
+	pairs := [][2]interface{}{
+		{nil, nil},
+		{true, true},
+		{0.0, math.Copysign(0, -1)},
+		{"abc", "abc"},
+		{clock, clock},
+	}
+
+	for _, pair := range pairs {
+		a, okA := hashValue(pair[0])
+		b, okB := hashValue(pair[1])
+		if !okA || !okB {
+			t.Errorf("want %v and %v to be hashable", pair[0], pair[1])
+		}
+		if a != b {
+			t.Errorf("equal values %v and %v hash differently", pair[0], pair[1])
+		}
+	}
+
+	if _, ok := hashValue([]interface{}{}); ok {
+		t.Error("want slice to be unhashable")
+	}
+}
@@ -0,0 +1,56 @@
+package lox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StaticError is a problem found in a script before it runs.
+type StaticError struct {
+	line    int
+	where   string
+	message string
+}
+
+func newStaticError(line int, message string) StaticError {
+	return StaticError{line, "", message}
+}
+
+func newTokenError(token Token, message string) StaticError {
+	if token.ttype == EOF {
+		return StaticError{token.line, " at end", message}
+	}
+	return StaticError{token.line, " at '" + token.lexeme + "'", message}
+}
+
+func (e StaticError) Error() string {
+	return fmt.Sprintf("[line %d] Error%s: %s", e.line, e.where, e.message)
+}
+
+// StaticErrors holds every error one stage found, one per line. It unwraps
+// to ErrScan, ErrParse or ErrResolve depending on the stage.
+type StaticErrors struct {
+	stage  error
+	errors []StaticError
+}
+
+func (e StaticErrors) Error() string {
+	lines := make([]string, len(e.errors))
+	for k, err := range e.errors {
+		lines[k] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (e StaticErrors) Unwrap() error {
+	return e.stage
+}
+
+// staticErrors returns nil if errors is empty, so stages can return it
+// directly.
+func staticErrors(stage error, errors []StaticError) error {
+	if len(errors) == 0 {
+		return nil
+	}
+	return StaticErrors{stage, errors}
+}
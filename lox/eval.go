@@ -0,0 +1,72 @@
+package lox
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+// Eval evaluates source as a single Lox expression, such as a rule like
+// "price * qty > 100", with vars as its only variables. The result is a Go
+// value: float64, string, bool, nil, or the Go value behind a host object.
+//
+// Referring to a variable missing from vars is reported as a static error
+// before anything runs. Eval never touches the standard streams.
+func Eval(source string, vars map[string]interface{}) (interface{}, error) {
+	return EvalContext(context.Background(), source, vars)
+}
+
+// EvalContext is like Eval but gives up when ctx is done.
+func EvalContext(ctx context.Context, source string, vars map[string]interface{}) (interface{}, error) {
+	interpreter := NewInterpreter()
+	interpreter.SetStdout(io.Discard)
+	interpreter.SetStdin(strings.NewReader(""))
+	for name, value := range vars {
+		interpreter.Define(name, value)
+	}
+
+	return interpreter.EvalContext(ctx, source)
+}
+
+// Eval evaluates source as a single expression using this interpreter's
+// globals and returns its value as the package-level Eval does.
+func (i *Interpreter) Eval(source string) (interface{}, error) {
+	return i.EvalContext(context.Background(), source)
+}
+
+// EvalContext is like Eval but runs under ctx.
+func (i *Interpreter) EvalContext(ctx context.Context, source string) (interface{}, error) {
+	tokens, err := Scan(source)
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := ParseExpression(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	locals := make(map[Expr]int)
+	resolver := NewResolver(locals)
+	resolver.globals = i.globals.values
+	resolver.resolveExpr(expr)
+	if err := staticErrors(ErrResolve, resolver.errors); err != nil {
+		return nil, err
+	}
+
+	previous := i.program
+	i.program = &Program{source: source, locals: locals}
+	defer func() {
+		i.program = previous
+	}()
+	defer i.start(ctx)()
+
+	value, err := i.evaluate(expr)
+	if err != nil {
+		return nil, err
+	}
+	if host, ok := value.(*HostObject); ok {
+		return host.value.Interface(), nil
+	}
+	return value, nil
+}
@@ -0,0 +1,64 @@
+package lox
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	vars := map[string]interface{}{
+		"price": 12.5,
+		"qty":   10,
+		"name":  "widget",
+	}
+
+	cases := []struct {
+		source string
+		want   interface{}
+	}{
+		{"price * qty > 100", true},
+		{"price * qty", 125.0},
+		{`name + "s"`, "widgets"},
+		{"qty < 5 or name == \"widget\"", true},
+		{"nil", nil},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.source, func(t *testing.T) {
+			got, err := Eval(cc.source, vars)
+			if err != nil {
+				t.Fatalf("want no error, got %v", err)
+			}
+			if got != cc.want {
+				t.Errorf("want %v, got %v", cc.want, got)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	cases := []struct {
+		source string
+		want   error
+	}{
+		{"1 +", ErrParse},
+		{"1 + 2;", ErrParse},
+		{"print 1", ErrParse},
+		{"price > 1", ErrResolve},
+		{"@", ErrScan},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.source, func(t *testing.T) {
+			if _, err := Eval(cc.source, nil); !errors.Is(err, cc.want) {
+				t.Errorf("want error %v, got %v", cc.want, err)
+			}
+		})
+	}
+
+	_, err := Eval(`"a" - 1`, nil)
+	var runtimeError RuntimeError
+	if !errors.As(err, &runtimeError) {
+		t.Errorf("want RuntimeError, got %v", err)
+	}
+}
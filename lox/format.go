@@ -0,0 +1,136 @@
+package lox
+
+import "strings"
+
+// formatIndent is one level of indentation in formatted source.
+const formatIndent = "    "
+
+// Format lays out source in the usual style: a statement per line, blocks
+// indented by four spaces, single spaces around operators and no more than
+// one blank line in a row. Comments are kept. Source with syntax errors is
+// left alone and the errors are returned.
+func Format(source string) (string, error) {
+	scanner := NewScanner(source)
+	scanner.keepComments = true
+	tokens := scanner.ScanTokens()
+	if err := staticErrors(ErrScan, scanner.errors); err != nil {
+		return "", err
+	}
+
+	var code []Token
+	for _, token := range tokens {
+		if token.ttype != COMMENT {
+			code = append(code, token)
+		}
+	}
+	if _, err := Parse(code); err != nil {
+		return "", err
+	}
+
+	f := formatter{}
+	for _, token := range tokens[:len(tokens)-1] {
+		f.token(token)
+	}
+	if f.prev != nil {
+		f.w.WriteString("\n")
+	}
+	return f.w.String(), nil
+}
+
+type formatter struct {
+	w      strings.Builder
+	indent int
+	parens int
+	prev   *Token
+
+	// newline is set when the next token starts a line.
+	newline bool
+	// unary is set after a unary minus, which takes no space after it.
+	unary bool
+}
+
+func (f *formatter) token(token Token) {
+	switch {
+	case token.ttype == COMMENT:
+		if f.prev != nil && f.prev.line == token.line {
+			f.w.WriteString(" ")
+		} else {
+			f.startLine(token)
+		}
+	case token.ttype == RIGHT_BRACE:
+		f.indent--
+		if f.prev.ttype != LEFT_BRACE {
+			f.startLine(token)
+		}
+	case f.newline && token.ttype == ELSE && f.prev.ttype == RIGHT_BRACE:
+		f.w.WriteString(" ")
+	case f.newline:
+		f.startLine(token)
+	case f.spaceBefore(token):
+		f.w.WriteString(" ")
+	}
+
+	f.w.WriteString(token.lexeme)
+	f.unary = token.ttype == MINUS && !f.endsOperand()
+
+	switch token.ttype {
+	case LEFT_PAREN:
+		f.parens++
+	case RIGHT_PAREN:
+		f.parens--
+	case LEFT_BRACE:
+		f.indent++
+	}
+	f.newline = token.ttype == COMMENT || token.ttype == LEFT_BRACE ||
+		token.ttype == RIGHT_BRACE || token.ttype == SEMICOLON && f.parens == 0
+
+	f.prev = &token
+}
+
+// startLine begins a new line for token, keeping one blank line if the
+// source had any, except at the start or end of a block.
+func (f *formatter) startLine(token Token) {
+	if f.prev != nil {
+		f.w.WriteString("\n")
+		if token.line > f.prev.line+1 && f.prev.ttype != LEFT_BRACE && token.ttype != RIGHT_BRACE {
+			f.w.WriteString("\n")
+		}
+	}
+	f.w.WriteString(strings.Repeat(formatIndent, f.indent))
+}
+
+func (f *formatter) spaceBefore(token Token) bool {
+	if f.prev == nil {
+		return false
+	}
+	if f.unary {
+		// Keep "- -x" from running together.
+		return token.ttype == MINUS
+	}
+	switch f.prev.ttype {
+	case LEFT_PAREN, DOT, BANG:
+		return false
+	}
+
+	switch token.ttype {
+	case SEMICOLON, COMMA, RIGHT_PAREN, DOT:
+		return false
+	case LEFT_PAREN:
+		// A call or a function's parameters.
+		return f.prev.ttype != IDENTIFIER && f.prev.ttype != RIGHT_PAREN
+	}
+	return true
+}
+
+// endsOperand reports whether the previous token ends an operand, making a
+// minus after it binary.
+func (f *formatter) endsOperand() bool {
+	if f.prev == nil {
+		return false
+	}
+	switch f.prev.ttype {
+	case IDENTIFIER, NUMBER, STRING, TRUE, FALSE, NIL, THIS, RIGHT_PAREN:
+		return true
+	}
+	return false
+}
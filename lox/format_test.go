@@ -0,0 +1,123 @@
+package lox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	source := `#!/usr/bin/env glox
+// Counts down.
+fun   count(n){if(n>1)count(n-1);else{print -n;}
+
+
+  print n ;   // trailing
+  return;}
+for(var i=0;i<3;i=i+1){print !(i==1) and f(i,-i).x;}
+{}
+`
+	want := `#!/usr/bin/env glox
+// Counts down.
+fun count(n) {
+    if (n > 1) count(n - 1);
+    else {
+        print -n;
+    }
+
+    print n; // trailing
+    return;
+}
+for (var i = 0; i < 3; i = i + 1) {
+    print !(i == 1) and f(i, -i).x;
+}
+{}
+`
+
+	got, err := Format(source)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+
+	again, _ := Format(got)
+	if again != got {
+		t.Errorf("formatting again changed\n%s\nto\n%s", got, again)
+	}
+}
+
+func TestFormatExamples(t *testing.T) {
+	files, _ := filepath.Glob("../examples/*.lox")
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			bytes, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Format(string(bytes))
+			if err != nil {
+				t.Fatalf("want no error, got %v", err)
+			}
+			if got != string(bytes) {
+				t.Errorf("want it unchanged, got\n%s", got)
+			}
+		})
+	}
+}
+
+func TestFormatSyntaxError(t *testing.T) {
+	if _, err := Format("print (1;"); !errors.Is(err, ErrParse) {
+		t.Errorf("want ErrParse, got %v", err)
+	}
+}
+
+func TestFormatCases(t *testing.T) {
+	cases := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"binary minus", "print a-b;", "print a - b;\n"},
+		{"unary minus", "print - 1;", "print -1;\n"},
+		{"minus minus", "print 1 - -1;", "print 1 - -1;\n"},
+		{"double negation", "print - -x;", "print - -x;\n"},
+		{"negated group", "var x = - (1);", "var x = -(1);\n"},
+		{"minus argument", "f( -1, - x);", "f(-1, -x);\n"},
+		{"minus after return", "fun f() { return -1; }", "fun f() {\n    return -1;\n}\n"},
+		{"not minus", "print ! -x;", "print !-x;\n"},
+		{
+			"else after brace",
+			"if (a) { print 1; }\nelse { print 2; }",
+			"if (a) {\n    print 1;\n} else {\n    print 2;\n}\n",
+		},
+		{
+			"else if",
+			"if (a) {print 1;} else if (b) {print 2;} else print 3;",
+			"if (a) {\n    print 1;\n} else if (b) {\n    print 2;\n} else print 3;\n",
+		},
+		{"else without braces", "if (a) print 1; else print 2;", "if (a) print 1;\nelse print 2;\n"},
+		{"line comment", "// a\n  // b\nprint 1;", "// a\n// b\nprint 1;\n"},
+		{"trailing comment", "print 1;// x", "print 1; // x\n"},
+		{"comment after brace", "fun f(){// c\nreturn 1;}", "fun f() { // c\n    return 1;\n}\n"},
+		{"comment in block", "{\n// c\nprint 1;\n}", "{\n    // c\n    print 1;\n}\n"},
+		{"blank lines", "print 1;\n\n\n\nprint 2;", "print 1;\n\nprint 2;\n"},
+		{"leading and trailing blank lines", "\n\nprint 1;\n\n", "print 1;\n"},
+		{"blank lines in block", "{\n\nprint 1;\n\nprint 2;\n\n}\n", "{\n    print 1;\n\n    print 2;\n}\n"},
+		{"empty", "", ""},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.name, func(t *testing.T) {
+			got, err := Format(cc.source)
+			if err != nil {
+				t.Fatalf("want no error, got %v", err)
+			}
+			if got != cc.want {
+				t.Errorf("want %q, got %q", cc.want, got)
+			}
+		})
+	}
+}
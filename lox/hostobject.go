@@ -0,0 +1,117 @@
+package lox
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// HostObject exposes a Go struct, or a pointer to one, to scripts. Exported
+// fields can be read and, through a pointer, assigned; exported methods can
+// be called. Values are converted the same way as for natives.
+type HostObject struct {
+	value       reflect.Value
+	interpreter *Interpreter
+}
+
+// NewHostObject wraps value, which should be a struct or a pointer to a
+// struct, so scripts can use its fields and methods. Natives that return such
+// values have them wrapped automatically.
+func (i *Interpreter) NewHostObject(value interface{}) *HostObject {
+	return &HostObject{reflect.ValueOf(value), i}
+}
+
+// AllowHostMembers restricts the fields and methods scripts can use on values
+// of sample's type to the ones named. Without an allow-list every exported
+// field and method is available. Pointer and struct types share a list.
+func (i *Interpreter) AllowHostMembers(sample interface{}, members ...string) {
+	if i.hostMembers == nil {
+		i.hostMembers = make(map[reflect.Type]map[string]bool)
+	}
+
+	allowed := make(map[string]bool)
+	for _, member := range members {
+		allowed[member] = true
+	}
+	i.hostMembers[structType(reflect.TypeOf(sample))] = allowed
+}
+
+func (o *HostObject) Get(name Token) (interface{}, error) {
+	if !o.allowed(name.lexeme) {
+		return nil, o.undefined(name)
+	}
+
+	if method := o.value.MethodByName(name.lexeme); method.IsValid() {
+		return newNative(name.lexeme, method)
+	}
+
+	field, ok := o.field(name.lexeme)
+	if !ok {
+		return nil, o.undefined(name)
+	}
+	return o.interpreter.fromGo(field)
+}
+
+func (o *HostObject) Set(name Token, value interface{}) error {
+	if !o.allowed(name.lexeme) {
+		return o.undefined(name)
+	}
+
+	field, ok := o.field(name.lexeme)
+	if !ok {
+		return o.undefined(name)
+	}
+	if !field.CanSet() {
+		return RuntimeError{
+			name,
+			fmt.Sprintf("can't assign to '%s' of a %v copy", name.lexeme, o.value.Type()),
+		}
+	}
+
+	converted, err := toGo(value, field.Type())
+	if err != nil {
+		return RuntimeError{name, fmt.Sprintf("property '%s': %v", name.lexeme, err)}
+	}
+	field.Set(converted)
+	return nil
+}
+
+func (o *HostObject) String() string {
+	return fmt.Sprintf("<host %v>", o.value.Type())
+}
+
+// field looks up an exported struct field, following a pointer if needed.
+func (o *HostObject) field(name string) (reflect.Value, bool) {
+	v := o.value
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	f, ok := v.Type().FieldByName(name)
+	if !ok || f.PkgPath != "" {
+		return reflect.Value{}, false
+	}
+	return v.FieldByIndex(f.Index), true
+}
+
+func (o *HostObject) allowed(name string) bool {
+	allowed, ok := o.interpreter.hostMembers[structType(o.value.Type())]
+	return !ok || allowed[name]
+}
+
+func (o *HostObject) undefined(name Token) error {
+	return RuntimeError{name, fmt.Sprintf("undefined property '%s'", name.lexeme)}
+}
+
+// structType is t with any pointer removed.
+func structType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,83 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type hostConfig struct {
+	Name    string
+	Retries int
+	secret  string
+}
+
+func (c *hostConfig) Greeting(greeting string) string {
+	return greeting + ", " + c.Name
+}
+
+func (c *hostConfig) Secret() string {
+	return c.secret
+}
+
+func TestHostObject(t *testing.T) {
+	config := &hostConfig{Name: "glox", Retries: 1, secret: "hunter2"}
+
+	interpreter := NewInterpreter()
+	interpreter.Define("config", config)
+
+	var got []interface{}
+	interpreter.DefineNative("record", func(v interface{}) { got = append(got, v) })
+
+	source := `
+record(config.Name);
+record(config.Greeting("hello"));
+config.Retries = config.Retries + 2;
+record(config == config);
+`
+	if err := interpret(interpreter, source); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := []interface{}{"glox", "hello, glox", true}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if config.Retries != 3 {
+		t.Errorf("want Retries %d, got %d", 3, config.Retries)
+	}
+}
+
+func TestHostObjectErrors(t *testing.T) {
+	interpreter := NewInterpreter()
+	interpreter.Define("config", &hostConfig{Name: "glox"})
+	interpreter.Define("copy", hostConfig{Name: "glox"})
+	interpreter.AllowHostMembers(&hostConfig{}, "Name", "Greeting")
+
+	cases := []struct {
+		source  string
+		message string
+	}{
+		{`config.secret;`, "undefined property 'secret'"},
+		{`config.Missing;`, "undefined property 'Missing'"},
+		{`config.Secret();`, "undefined property 'Secret'"},
+		{`config.Retries = 1;`, "undefined property 'Retries'"},
+		{`config.Name = 1;`, "property 'Name': can't use number as string"},
+		{`copy.Name = "x";`, "can't assign to 'Name' of a lox.hostConfig copy"},
+		{`"str".length;`, "only instances have properties"},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.source, func(t *testing.T) {
+			err := interpret(interpreter, cc.source)
+
+			var runtimeError RuntimeError
+			if !errors.As(err, &runtimeError) {
+				t.Fatalf("want RuntimeError, got %v", err)
+			}
+			if runtimeError.message != cc.message {
+				t.Errorf("want message %q, got %q", cc.message, runtimeError.message)
+			}
+		})
+	}
+}
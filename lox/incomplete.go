@@ -0,0 +1,80 @@
+package lox
+
+// Incomplete reports whether source stops part way through, so that an
+// interactive prompt should read more lines before running it. That is the
+// case when a string, parenthesis or brace is left open, when the source
+// ends with an operator or keyword that must be followed by more, or when it
+// ends with the header of an if, while or for statement that lacks a body,
+// or when a var, print, return or import statement is missing its
+// semicolon.
+//
+// A trailing expression without a semicolon counts as complete.
+func Incomplete(source string) bool {
+	scanner := NewScanner(source)
+	tokens := scanner.ScanTokens()
+	if scanner.openString {
+		return true
+	}
+
+	// Drop the EOF token.
+	tokens = tokens[:len(tokens)-1]
+	if len(tokens) == 0 {
+		return false
+	}
+
+	// Track open brackets, noting which parentheses follow if, while or for.
+	type bracket struct {
+		ttype  TokenType
+		header bool
+	}
+	var open []bracket
+	var closedHeader bool
+
+	// The first token of the last statement at the top level.
+	var statement TokenType
+	atStart := true
+
+	for k, token := range tokens {
+		closedHeader = false
+		if atStart && len(open) == 0 {
+			statement = token.ttype
+		}
+		atStart = token.ttype == SEMICOLON || token.ttype == RIGHT_BRACE
+
+		switch token.ttype {
+		case LEFT_PAREN:
+			header := k > 0 && (tokens[k-1].ttype == IF ||
+				tokens[k-1].ttype == WHILE || tokens[k-1].ttype == FOR)
+			open = append(open, bracket{LEFT_PAREN, header})
+		case LEFT_BRACE:
+			open = append(open, bracket{LEFT_BRACE, false})
+		case RIGHT_PAREN, RIGHT_BRACE:
+			if len(open) == 0 {
+				// Unbalanced the other way, which more input won't fix.
+				return false
+			}
+			closedHeader = open[len(open)-1].header
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) > 0 || closedHeader {
+		return true
+	}
+
+	last := tokens[len(tokens)-1].ttype
+	switch statement {
+	case VAR, PRINT, RETURN, IMPORT:
+		if last != SEMICOLON {
+			return true
+		}
+	}
+
+	switch last {
+	case COMMA, DOT, MINUS, PLUS, SLASH, STAR,
+		BANG, BANG_EQUAL, EQUAL, EQUAL_EQUAL,
+		GREATER, GREATER_EQUAL, LESS, LESS_EQUAL,
+		AND, OR, ELSE, FUN, VAR, CLASS, IMPORT, PRINT, RETURN:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package lox
+
+import "testing"
+
+func TestIncomplete(t *testing.T) {
+	cases := []struct {
+		source string
+		want   bool
+	}{
+		{"", false},
+		{"print 1;", false},
+		{"1 + 2", false},
+		{"fun f() {", true},
+		{"fun f() {\n  print 1;\n}", false},
+		{"print (1 +", true},
+		{"print 1 +", true},
+		{"print 1", true},
+		{"var x = 1", true},
+		{"{ print 1; } var x = 1", true},
+		{"fun f() {\n  return 1", true},
+		{`print "abc`, true},
+		{`print "a(b";`, false},
+		{"if (x)", true},
+		{"if (x) print 1;", false},
+		{"if (x) print 1; else", true},
+		{"while (f(x))", true},
+		{"f(x)", false},
+		{"}", false},
+		{"// just a comment (", false},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.source, func(t *testing.T) {
+			if got := Incomplete(cc.source); got != cc.want {
+				t.Errorf("want %v, got %v", cc.want, got)
+			}
+		})
+	}
+}
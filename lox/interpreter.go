@@ -0,0 +1,659 @@
+package lox
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+)
+
+//
+// native functions
+//
+
+func clock() float64 {
+	t := time.Now()
+	return float64(t.UnixMilli()) / 1000.0
+}
+
+// readLine returns the next line of input without its line ending, or nil
+// at the end of input.
+func (i *Interpreter) readLine() (interface{}, error) {
+	line, err := i.stdin.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return nil, nil
+	}
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// exit stops the script with the given status by returning an ExitError,
+// which unwinds out of Interpret.
+func exit(code int) error {
+	return ExitError{code}
+}
+
+//--------------------------------------------------------------------------------
+// interpreter
+//
+
+// DefaultMaxCallDepth is the number of nested calls an Interpreter allows
+// before it raises a stack overflow error.
+const DefaultMaxCallDepth = 4096
+
+// ErrStepLimitExceeded is returned when a script executes more statements
+// than its step budget allows.
+var ErrStepLimitExceeded = errors.New("step limit exceeded")
+
+// Approximate sizes, in bytes, charged against the memory budget.
+const (
+	sizeString      = 16 // plus one byte per character
+	sizeBinding     = 48
+	sizeEnvironment = 64
+	sizeClosure     = 32
+)
+
+type Interpreter struct {
+	globals     *Environment
+	environment *Environment
+
+	// program is the program whose code is running. Its resolution table
+	// says where local variables are defined.
+	program *Program
+
+	maxCallDepth int
+	frames       []callFrame
+
+	ctx      context.Context
+	maxSteps int
+	steps    int
+
+	maxMemory int
+	allocated int
+
+	hostMembers map[reflect.Type]map[string]bool
+
+	stdout io.Writer
+	stdin  *bufio.Reader
+
+	loader      ModuleLoader
+	module      string
+	modules     map[string]bool
+	hostModules map[string]*hostModule
+}
+
+// callFrame records a function being called and where it was called from.
+type callFrame struct {
+	function LoxCallable
+	paren    Token
+}
+
+func NewInterpreter() *Interpreter {
+	globals := NewEnvironment(nil)
+
+	interpreter := &Interpreter{
+		globals:      globals,
+		environment:  globals,
+		program:      &Program{locals: make(map[Expr]int)},
+		maxCallDepth: DefaultMaxCallDepth,
+		ctx:          context.Background(),
+		stdout:       os.Stdout,
+		stdin:        bufio.NewReader(os.Stdin),
+		loader:       OSLoader{},
+		modules:      make(map[string]bool),
+		hostModules:  make(map[string]*hostModule),
+	}
+	interpreter.DefineNative("clock", clock)
+	interpreter.DefineNative("readLine", interpreter.readLine)
+	interpreter.DefineNative("exit", exit)
+
+	return interpreter
+}
+
+// Define binds name to value in the global scope. Go values are converted
+// the same way native results are, so structs become host objects.
+func (i *Interpreter) Define(name string, value interface{}) {
+	if value != nil {
+		value, _ = i.fromGo(reflect.ValueOf(value))
+	}
+	i.globals.define(name, value)
+}
+
+// SetStdout sets where print statements write. It defaults to os.Stdout.
+func (i *Interpreter) SetStdout(w io.Writer) {
+	i.stdout = w
+}
+
+// SetStdin sets where the readLine native reads from. It defaults to
+// os.Stdin.
+func (i *Interpreter) SetStdin(r io.Reader) {
+	i.stdin = bufio.NewReader(r)
+}
+
+// SetMaxCallDepth sets how deeply calls may nest before a stack overflow
+// error is raised. A depth of zero or less disables the check.
+func (i *Interpreter) SetMaxCallDepth(depth int) {
+	i.maxCallDepth = depth
+}
+
+// SetMaxSteps sets how many statements a single call to Interpret may
+// execute. A budget of zero or less means no limit.
+func (i *Interpreter) SetMaxSteps(steps int) {
+	i.maxSteps = steps
+}
+
+// SetMaxMemory sets roughly how many bytes a single call to Interpret may
+// allocate for strings, variables, environments and closures. The budget is
+// cumulative: memory is charged when allocated and never credited back when
+// it becomes garbage. A budget of zero or less means no limit.
+func (i *Interpreter) SetMaxMemory(bytes int) {
+	i.maxMemory = bytes
+}
+
+func (i *Interpreter) Interpret(stmts []Stmt) error {
+	return i.InterpretContext(context.Background(), stmts)
+}
+
+// InterpretContext is like Interpret but runs under ctx.
+func (i *Interpreter) InterpretContext(ctx context.Context, stmts []Stmt) error {
+	defer i.start(ctx)()
+
+	for _, stmt := range stmts {
+		if err := i.execute(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// start begins a run under ctx with fresh budgets. Calling the returned
+// function ends it, putting back the context and budgets of any run it was
+// nested in.
+func (i *Interpreter) start(ctx context.Context) func() {
+	previous, steps, allocated := i.ctx, i.steps, i.allocated
+	i.ctx, i.steps, i.allocated = ctx, 0, 0
+	return func() {
+		i.ctx, i.steps, i.allocated = previous, steps, allocated
+	}
+}
+
+//
+// Visit Stmt
+//
+
+func (i *Interpreter) VisitExpressionStmt(stmt Expression) (interface{}, error) {
+	return i.evaluate(stmt.expression)
+}
+
+func (i *Interpreter) VisitPrintStmt(stmt Print) (interface{}, error) {
+	value, err := i.evaluate(stmt.expression)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintln(i.stdout, value)
+	return nil, nil
+}
+
+// VisitEchoStmt shows the value of an expression typed at the prompt.
+func (i *Interpreter) VisitEchoStmt(stmt Echo) (interface{}, error) {
+	value, err := i.evaluate(stmt.expression)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintln(i.stdout, Stringify(value))
+	return nil, nil
+}
+
+// Stringify formats value as Lox shows it, with nil as "nil" and whole
+// numbers without a fraction.
+func Stringify(value interface{}) string {
+	if value == nil {
+		return "nil"
+	}
+	return fmt.Sprint(value)
+}
+
+func (i *Interpreter) VisitVarStmt(stmt Var) (interface{}, error) {
+	if err := i.allocate(stmt.name, sizeBinding); err != nil {
+		return nil, err
+	}
+
+	if stmt.initializer == nil {
+		i.environment.define(stmt.name.lexeme, nil)
+		return nil, nil
+	}
+
+	value, err := i.evaluate(*stmt.initializer)
+	if err != nil {
+		return nil, err
+	}
+
+	i.environment.define(stmt.name.lexeme, value)
+	return nil, nil
+}
+
+func (i *Interpreter) VisitBlockStmt(stmt Block) (interface{}, error) {
+	if err := i.allocate(stmt.brace, sizeEnvironment); err != nil {
+		return nil, err
+	}
+	return nil, i.executeBlock(stmt.statements, NewEnvironment(i.environment))
+}
+
+func (i *Interpreter) VisitIfStmt(stmt If) (interface{}, error) {
+	evaled, err := i.evaluate(stmt.condition)
+	if err != nil {
+		return nil, err
+	}
+
+	if isTruthy(evaled) {
+		return nil, i.execute(*stmt.thenBranch)
+	}
+	if stmt.elseBranch != nil {
+		return nil, i.execute(*stmt.elseBranch)
+	}
+
+	return nil, nil
+}
+
+func (i *Interpreter) VisitWhileStmt(stmt While) (interface{}, error) {
+	for {
+		v, err := i.evaluate(stmt.condition)
+		if err != nil {
+			return nil, err
+		}
+		if !isTruthy(v) {
+			break
+		}
+
+		if err := i.execute(stmt.body); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+func (i *Interpreter) VisitFunctionStmt(stmt Function) (interface{}, error) {
+	if err := i.allocate(stmt.name, sizeClosure+sizeBinding); err != nil {
+		return nil, err
+	}
+
+	function := NewLoxFunction(stmt, i.environment, i.program)
+	i.environment.define(stmt.name.lexeme, function)
+	return nil, nil
+}
+
+func (i *Interpreter) VisitReturnStmt(stmt Return) (interface{}, error) {
+	var value interface{} = nil
+	if stmt.value != nil {
+		v, err := i.evaluate(*stmt.value)
+		if err != nil {
+			return nil, err
+		}
+		value = v
+	}
+
+	return nil, ReturnValue{value}
+}
+
+func (i *Interpreter) execute(stmt Stmt) error {
+	if err := i.step(); err != nil {
+		return err
+	}
+
+	_, err := stmt.Accept(i)
+	return err
+}
+
+// allocate charges size bytes against the memory budget, blaming token if
+// the budget runs out. Every runtime value that grows with the script's
+// behaviour should be charged here before it is created.
+func (i *Interpreter) allocate(token Token, size int) error {
+	i.allocated += size
+	if i.maxMemory > 0 && i.allocated > i.maxMemory {
+		return RuntimeError{token, ErrMemoryLimitExceeded}
+	}
+	return nil
+}
+
+// step charges one statement against the budget and checks for cancellation.
+func (i *Interpreter) step() error {
+	i.steps++
+	if i.maxSteps > 0 && i.steps > i.maxSteps {
+		return ErrStepLimitExceeded
+	}
+
+	select {
+	case <-i.ctx.Done():
+		return i.ctx.Err()
+	default:
+		return nil
+	}
+}
+
+func (i *Interpreter) executeBlock(statements []Stmt, environment *Environment) error {
+	previous := i.environment
+	defer func() {
+		i.environment = previous
+	}()
+
+	i.environment = environment
+	for _, stmt := range statements {
+		if err := i.execute(stmt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//
+// Visit Expr
+//
+
+func (i *Interpreter) VisitLiteralExpr(expr Literal) (interface{}, error) {
+	return expr.value, nil
+}
+
+func (i *Interpreter) VisitGroupingExpr(expr Grouping) (interface{}, error) {
+	return i.evaluate(expr.expression)
+}
+
+func (i *Interpreter) VisitUnaryExpr(expr Unary) (interface{}, error) {
+	right, err := i.evaluate(expr.right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch expr.operator.ttype {
+	case MINUS:
+		if err := checkNumberOperand(expr.operator, right); err != nil {
+			return nil, err
+		}
+		return -(right.(float64)), nil
+	case BANG:
+		return !(isTruthy(right)), nil
+	}
+
+	// unreachable
+	return nil, nil
+}
+
+func (i *Interpreter) VisitBinaryExpr(expr Binary) (interface{}, error) {
+	left, err := i.evaluate(expr.left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := i.evaluate(expr.right)
+	if err != nil {
+		return nil, err
+	}
+
+	switch expr.operator.ttype {
+	case GREATER:
+		if err := checkNumberOperands(expr.operator, left, right); err != nil {
+			return nil, err
+		}
+		return left.(float64) > right.(float64), nil
+	case GREATER_EQUAL:
+		if err := checkNumberOperands(expr.operator, left, right); err != nil {
+			return nil, err
+		}
+		return left.(float64) >= right.(float64), nil
+	case LESS:
+		if err := checkNumberOperands(expr.operator, left, right); err != nil {
+			return nil, err
+		}
+		return left.(float64) < right.(float64), nil
+	case LESS_EQUAL:
+		if err := checkNumberOperands(expr.operator, left, right); err != nil {
+			return nil, err
+		}
+		return left.(float64) <= right.(float64), nil
+
+	case BANG_EQUAL:
+		return !isEqual(left, right), nil
+	case EQUAL_EQUAL:
+		return isEqual(left, right), nil
+
+	case MINUS:
+		if err := checkNumberOperands(expr.operator, left, right); err != nil {
+			return nil, err
+		}
+		return left.(float64) - right.(float64), nil
+	case PLUS:
+		if l, ok := left.(float64); ok {
+			if r, ok := right.(float64); ok {
+				return l + r, nil
+			}
+		}
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				if err := i.allocate(expr.operator, sizeString+len(l)+len(r)); err != nil {
+					return nil, err
+				}
+				return l + r, nil
+			}
+		}
+		return nil, RuntimeError{expr.operator, ErrOperandsMustBeNumsOrStrs}
+	case SLASH:
+		if err := checkNumberOperands(expr.operator, left, right); err != nil {
+			return nil, err
+		}
+		return left.(float64) / right.(float64), nil
+	case STAR:
+		if err := checkNumberOperands(expr.operator, left, right); err != nil {
+			return nil, err
+		}
+		return left.(float64) * right.(float64), nil
+	}
+
+	// unreachable
+	return nil, nil
+}
+
+func (i *Interpreter) VisitVariableExpr(expr Variable) (interface{}, error) {
+	//return i.environment.get(expr.name)
+	return i.lookUpVariable(expr.name, expr)
+}
+
+func (i *Interpreter) lookUpVariable(name Token, expr Expr) (interface{}, error) {
+	if distance, ok := i.program.locals[expr]; ok {
+		value, _ := i.environment.getAt(distance, name.lexeme)
+		return value, nil
+	} else {
+		value, err := i.globals.get(name)
+		return value, err
+	}
+}
+
+func (i *Interpreter) VisitAssignExpr(expr Assign) (interface{}, error) {
+	value, err := i.evaluate(expr.value)
+	if err != nil {
+		return nil, err
+	}
+
+	var exp Expr = expr
+	distance, ok := i.program.locals[exp]
+	if ok {
+		i.environment.assignAt(distance, expr.name, value)
+	} else {
+		i.globals.assign(expr.name, value)
+	}
+
+	return value, nil
+}
+
+func (i *Interpreter) VisitLogicalExpr(expr Logical) (interface{}, error) {
+	left, err := i.evaluate(expr.left)
+	if err != nil {
+		return nil, err
+	}
+
+	if expr.operator.ttype == OR {
+		if isTruthy(left) {
+			return left, nil
+		}
+	} else {
+		if !isTruthy(left) {
+			return left, nil
+		}
+	}
+
+	return i.evaluate(expr.right)
+}
+
+func (i *Interpreter) VisitCallExpr(expr Call) (interface{}, error) {
+	callee, err := i.evaluate(expr.callee)
+	if err != nil {
+		return nil, err
+	}
+
+	var arguments []interface{}
+	for _, argument := range expr.arguments {
+		evaled, err := i.evaluate(argument)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, evaled)
+	}
+
+	function, ok := callee.(LoxCallable)
+	if !ok {
+		return nil, RuntimeError{
+			expr.paren, "can only call functions and classes",
+		}
+	}
+
+	return i.call(function, expr.paren, arguments)
+}
+
+// call invokes function on behalf of the call expression ending at paren,
+// checking the argument count and call depth first.
+func (i *Interpreter) call(function LoxCallable, paren Token, arguments []interface{}) (interface{}, error) {
+	if arity := function.Arity(); arity >= 0 && len(arguments) != arity {
+		return nil, RuntimeError{
+			paren,
+			fmt.Sprintf("expected %d arguments but got %d",
+				arity, len(arguments)),
+		}
+	}
+
+	if i.maxCallDepth > 0 && len(i.frames) >= i.maxCallDepth {
+		return nil, StackOverflowError{
+			RuntimeError{paren, "Stack overflow."},
+			i.stackTrace(),
+		}
+	}
+
+	i.frames = append(i.frames, callFrame{function, paren})
+	defer func() {
+		i.frames = i.frames[:len(i.frames)-1]
+	}()
+
+	return function.Call(i, arguments)
+}
+
+// callSite is the call expression's closing parenthesis for the innermost
+// active call, which natives blame for their errors.
+func (i *Interpreter) callSite() Token {
+	if len(i.frames) == 0 {
+		return Token{}
+	}
+	return i.frames[len(i.frames)-1].paren
+}
+
+// stackTrace describes the active calls, innermost first. Only the innermost
+// and outermost few frames are kept so that deep recursion stays readable.
+func (i *Interpreter) stackTrace() []string {
+	const keep = 10
+
+	var trace []string
+	for n := len(i.frames) - 1; n >= 0; n-- {
+		if len(i.frames) > 2*keep && n == len(i.frames)-1-keep {
+			trace = append(trace, fmt.Sprintf("... %d more frames", len(i.frames)-2*keep))
+			n -= len(i.frames) - 2*keep
+		}
+		frame := i.frames[n]
+		trace = append(trace, fmt.Sprintf("[line %d] in %v", frame.paren.line, frame.function))
+	}
+	return trace
+}
+
+func (i *Interpreter) VisitGetExpr(expr Get) (interface{}, error) {
+	object, err := i.evaluate(expr.object)
+	if err != nil {
+		return nil, err
+	}
+
+	if object, ok := object.(LoxObject); ok {
+		return object.Get(expr.name)
+	}
+
+	return nil, RuntimeError{expr.name, "only instances have properties"}
+}
+
+func (i *Interpreter) VisitSetExpr(expr Set) (interface{}, error) {
+	object, err := i.evaluate(expr.object)
+	if err != nil {
+		return nil, err
+	}
+
+	target, ok := object.(LoxObject)
+	if !ok {
+		return nil, RuntimeError{expr.name, "only instances have fields"}
+	}
+
+	value, err := i.evaluate(expr.value)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := target.Set(expr.name, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (i *Interpreter) evaluate(expr Expr) (interface{}, error) {
+	return expr.Accept(i)
+}
+
+func isTruthy(object interface{}) bool {
+	switch v := object.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	default:
+		return true
+	}
+}
+
+func checkNumberOperand(operator Token, operand interface{}) error {
+	_, ok := operand.(float64)
+	if ok {
+		return nil
+	}
+
+	return RuntimeError{operator, ErrOperandMustBeANumber}
+}
+
+func checkNumberOperands(operator Token, left, right interface{}) error {
+	_, ok1 := left.(float64)
+	_, ok2 := right.(float64)
+	if ok1 && ok2 {
+		return nil
+	}
+
+	return RuntimeError{operator, ErrOperandsMustBeNumbers}
+}
@@ -0,0 +1,77 @@
+package lox
+
+import "testing"
+
+func BenchmarkFib(b *testing.B) {
+	benchmarkSource(b, `
+fun fib(n) {
+    if (n <= 1) return n;
+    return fib(n - 2) + fib(n - 1);
+}
+fib(15);
+`)
+}
+
+func BenchmarkClosureCounter(b *testing.B) {
+	benchmarkSource(b, `
+fun makeCounter() {
+    var i = 0;
+    fun count() {
+        i = i + 1;
+        return i;
+    }
+    return count;
+}
+
+var counter = makeCounter();
+var n = 0;
+while (n < 1000) {
+    counter();
+    n = n + 1;
+}
+`)
+}
+
+func BenchmarkStringConcat(b *testing.B) {
+	benchmarkSource(b, `
+var s = "";
+var n = 0;
+while (n < 1000) {
+    s = s + "x";
+    n = n + 1;
+}
+`)
+}
+
+func BenchmarkDeepScopes(b *testing.B) {
+	benchmarkSource(b, `
+var n = 0;
+while (n < 200) {
+    var a = n;
+    {
+        var b = a + 1;
+        {
+            var c = b + 1;
+            {
+                var d = c + 1;
+                {
+                    var e = d + 1;
+                    n = e - 3;
+                }
+            }
+        }
+    }
+}
+`)
+}
+
+func benchmarkSource(b *testing.B, source string) {
+	b.Helper()
+	b.ReportAllocs()
+
+	for n := 0; n < b.N; n++ {
+		if err := interpret(NewInterpreter(), source); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package lox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	minus = NewToken(MINUS, "-", nil, 1)
+	plus  = NewToken(PLUS, "+", nil, 2)
+)
+
+func TestInterpreter(t *testing.T) {
+	cases := []struct {
+		expr Expr
+		want interface{}
+	}{
+		{
+			expr: Literal{1},
+			want: 1,
+		},
+		{
+			expr: Binary{Literal{"abc"}, plus, Literal{"123"}},
+			want: "abc123",
+		},
+	}
+
+	interpreter := Interpreter{}
+	for _, cc := range cases {
+		got, err := interpreter.evaluate(cc.expr)
+
+		if err != nil {
+			t.Errorf("want no error, got %v", err)
+		}
+
+		if !reflect.DeepEqual(got, cc.want) {
+			t.Errorf("want %v, got %v", cc.want, got)
+		}
+	}
+}
+
+func TestInterpreterError(t *testing.T) {
+	cases := []struct {
+		expr Expr
+		want error
+	}{
+		{
+			expr: Unary{minus, Literal{"string"}},
+			want: RuntimeError{minus, ErrOperandMustBeANumber},
+		},
+	}
+
+	interpreter := Interpreter{}
+	for _, cc := range cases {
+		_, err := interpreter.evaluate(cc.expr)
+
+		if err == nil {
+			t.Error("want error, got none")
+		}
+
+		if !errors.Is(err, cc.want) {
+			t.Errorf("want error %v, got %v", cc.want, err)
+		}
+	}
+}
+
+func TestStackOverflow(t *testing.T) {
+	source := `
+fun recurse(n) {
+    return recurse(n + 1);
+}
+recurse(0);
+`
+	interpreter := NewInterpreter()
+	interpreter.SetMaxCallDepth(100)
+
+	err := interpret(interpreter, source)
+
+	var runtimeError RuntimeError
+	if !errors.As(err, &runtimeError) {
+		t.Fatalf("want RuntimeError, got %v", err)
+	}
+	if runtimeError.message != "Stack overflow." {
+		t.Errorf("want message %q, got %q", "Stack overflow.", runtimeError.message)
+	}
+
+	var overflow StackOverflowError
+	if !errors.As(err, &overflow) {
+		t.Fatalf("want StackOverflowError, got %v", err)
+	}
+	if want := "[line 3] in <fn recurse>"; overflow.trace[0] != want {
+		t.Errorf("want innermost frame %q, got %q", want, overflow.trace[0])
+	}
+	if len(interpreter.frames) != 0 {
+		t.Errorf("want call stack unwound, got %d frames", len(interpreter.frames))
+	}
+}
+
+func TestExecutionBudgets(t *testing.T) {
+	source := "while (true) {}"
+
+	t.Run("max steps", func(t *testing.T) {
+		interpreter := NewInterpreter()
+		interpreter.SetMaxSteps(1000)
+
+		err := interpret(interpreter, source)
+		if !errors.Is(err, ErrStepLimitExceeded) {
+			t.Errorf("want error %v, got %v", ErrStepLimitExceeded, err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := interpretContext(ctx, NewInterpreter(), source)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("want error %v, got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
+
+func TestMemoryLimit(t *testing.T) {
+	source := `
+var s = "x";
+while (true) {
+    s = s + s;
+}
+`
+	interpreter := NewInterpreter()
+	interpreter.SetMaxMemory(1 << 20)
+
+	err := interpret(interpreter, source)
+
+	var runtimeError RuntimeError
+	if !errors.As(err, &runtimeError) {
+		t.Fatalf("want RuntimeError, got %v", err)
+	}
+	if runtimeError.message != ErrMemoryLimitExceeded {
+		t.Errorf("want message %q, got %q", ErrMemoryLimitExceeded, runtimeError.message)
+	}
+}
+
+func TestMemoryLimitBlocks(t *testing.T) {
+	// Only the loop body's scope is allocated, once per iteration. The step
+	// limit stops the loop if the scopes aren't charged.
+	source := `
+var i = 0;
+while (true) {
+    i = i + 1;
+}
+`
+	interpreter := NewInterpreter()
+	interpreter.SetMaxMemory(1 << 16)
+	interpreter.SetMaxSteps(1 << 20)
+
+	err := interpret(interpreter, source)
+
+	var runtimeError RuntimeError
+	if !errors.As(err, &runtimeError) || runtimeError.message != ErrMemoryLimitExceeded {
+		t.Errorf("want %q, got %v", ErrMemoryLimitExceeded, err)
+	}
+}
+
+func TestSameNameOnOneLine(t *testing.T) {
+	source := `
+var sum = 0;
+for (var i = 0; i < 3; i = i + 1) sum = sum + i;
+if (sum != 3) missing();
+`
+	if err := interpret(NewInterpreter(), source); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+}
+
+func TestStdio(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	interpreter := NewInterpreter()
+	interpreter.SetStdout(stdout)
+	interpreter.SetStdin(strings.NewReader("first\nsecond"))
+
+	source := `
+print readLine();
+print readLine();
+print readLine() == nil;
+`
+	if err := interpret(interpreter, source); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if want := "first\nsecond\ntrue\n"; stdout.String() != want {
+		t.Errorf("want output %q, got %q", want, stdout.String())
+	}
+}
+
+func TestExit(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	interpreter := NewInterpreter()
+	interpreter.SetStdout(stdout)
+
+	source := `
+fun stop() {
+    print "stopping";
+    exit(3);
+    print "after exit";
+}
+stop();
+print "after call";
+`
+	err := interpret(interpreter, source)
+	var exit ExitError
+	if !errors.As(err, &exit) || exit.Code != 3 {
+		t.Fatalf("want ExitError with code 3, got %v", err)
+	}
+	if want := "stopping\n"; stdout.String() != want {
+		t.Errorf("want output %q, got %q", want, stdout.String())
+	}
+}
+
+func interpret(interpreter *Interpreter, source string) error {
+	return interpretContext(context.Background(), interpreter, source)
+}
+
+func interpretContext(ctx context.Context, interpreter *Interpreter, source string) error {
+	tokens, err := Scan(source)
+	if err != nil {
+		return err
+	}
+	stmts, err := Parse(tokens)
+	if err != nil {
+		return err
+	}
+	if err := Resolve(interpreter, stmts); err != nil {
+		return err
+	}
+
+	return interpreter.InterpretContext(ctx, stmts)
+}
@@ -0,0 +1,54 @@
+package lox
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// List is a fixed sequence of Lox values, such as the arguments a host passes
+// to a script. Scripts read its length property and call get(index).
+type List struct {
+	elements []interface{}
+}
+
+// NewList makes a list of Go values.
+func (i *Interpreter) NewList(elements ...interface{}) *List {
+	list := &List{make([]interface{}, len(elements))}
+	for k, element := range elements {
+		if element != nil {
+			element, _ = i.fromGo(reflect.ValueOf(element))
+		}
+		list.elements[k] = element
+	}
+	return list
+}
+
+func (l *List) Get(name Token) (interface{}, error) {
+	switch name.lexeme {
+	case "length":
+		return float64(len(l.elements)), nil
+	case "get":
+		return newNative("get", reflect.ValueOf(l.get))
+	}
+	return nil, RuntimeError{name, fmt.Sprintf("undefined property '%s'", name.lexeme)}
+}
+
+func (l *List) Set(name Token, value interface{}) error {
+	return RuntimeError{name, "can't assign to a list's properties"}
+}
+
+func (l *List) get(index int) (interface{}, error) {
+	if index < 0 || index >= len(l.elements) {
+		return nil, fmt.Errorf("index %d out of range for list of length %d", index, len(l.elements))
+	}
+	return l.elements[index], nil
+}
+
+func (l *List) String() string {
+	elements := make([]string, len(l.elements))
+	for k, element := range l.elements {
+		elements[k] = Stringify(element)
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
@@ -0,0 +1,36 @@
+package lox
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	interpreter := NewInterpreter()
+	interpreter.SetStdout(stdout)
+	interpreter.Define("list", interpreter.NewList("a", 2, nil, true))
+
+	source := `
+print list;
+print list.length;
+for (var i = 0; i < list.length; i = i + 1) print list.get(i);
+`
+	if err := interpret(interpreter, source); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if want := "[a, 2, nil, true]\n4\na\n2\n<nil>\ntrue\n"; stdout.String() != want {
+		t.Errorf("want output %q, got %q", want, stdout.String())
+	}
+
+	for _, source := range []string{
+		"list.get(4);",
+		"list.get(0.5);",
+		"list.size;",
+		"list.length = 1;",
+	} {
+		if err := interpret(interpreter, source); err == nil {
+			t.Errorf("%s: want an error, got none", source)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package lox
+
+// LoxCallable is implemented by values that scripts can call. An Arity of -1
+// means any number of arguments is accepted.
+type LoxCallable interface {
+	Arity() int
+	Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error)
+}
@@ -0,0 +1,53 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+)
+
+type LoxFunction struct {
+	declaration Function
+	closure     *Environment
+	program     *Program
+}
+
+// NewLoxFunction returns a pointer so that every function object has its
+// own identity, which is what Lox equality compares. program is the program
+// that declared the function.
+func NewLoxFunction(declaration Function, closure *Environment, program *Program) *LoxFunction {
+	return &LoxFunction{declaration, closure, program}
+}
+
+func (f *LoxFunction) Arity() int {
+	return len(f.declaration.params)
+}
+
+func (f *LoxFunction) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	size := sizeEnvironment + sizeBinding*len(f.declaration.params)
+	if err := interpreter.allocate(f.declaration.name, size); err != nil {
+		return nil, err
+	}
+
+	previous := interpreter.program
+	interpreter.program = f.program
+	defer func() {
+		interpreter.program = previous
+	}()
+
+	environment := NewEnvironment(f.closure)
+	for i := 0; i < len(f.declaration.params); i++ {
+		environment.define(f.declaration.params[i].lexeme, arguments[i])
+	}
+
+	err := interpreter.executeBlock(f.declaration.body, environment)
+	var returnValue ReturnValue
+	if errors.As(err, &returnValue) {
+		return returnValue.value, nil
+	}
+
+	return nil, err
+}
+
+func (f *LoxFunction) String() string {
+	return fmt.Sprintf("<fn %s>", f.declaration.name.lexeme)
+}
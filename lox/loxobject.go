@@ -0,0 +1,8 @@
+package lox
+
+// LoxObject is implemented by values with properties, which scripts read with
+// object.name and assign with object.name = value.
+type LoxObject interface {
+	Get(name Token) (interface{}, error)
+	Set(name Token, value interface{}) error
+}
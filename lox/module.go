@@ -0,0 +1,186 @@
+package lox
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// hostPrefix marks import paths that name modules provided by the host
+// program rather than loaded as source.
+const hostPrefix = "host:"
+
+// ModuleLoader finds the source of the modules that import statements name.
+type ModuleLoader interface {
+	// Resolve turns the path written in an import statement into the
+	// module's canonical name. from is the canonical name of the importing
+	// module, or "" for the main script.
+	Resolve(from, path string) (string, error)
+
+	// Load returns the source of the module with the given canonical name.
+	Load(name string) (string, error)
+}
+
+// OSLoader loads modules from the operating system's filesystem. Paths are
+// relative to the importing module, or to Dir for the main script.
+type OSLoader struct {
+	Dir string
+}
+
+func (l OSLoader) Resolve(from, name string) (string, error) {
+	if !filepath.IsAbs(name) {
+		dir := l.Dir
+		if from != "" {
+			dir = filepath.Dir(from)
+		}
+		name = filepath.Join(dir, name)
+	}
+	return filepath.Abs(name)
+}
+
+func (l OSLoader) Load(name string) (string, error) {
+	bytes, err := os.ReadFile(name)
+	return string(bytes), err
+}
+
+// FSLoader loads modules from an fs.FS, such as an embed.FS. Paths are
+// slash-separated and relative to the importing module, or to the root of
+// FS for the main script.
+type FSLoader struct {
+	FS fs.FS
+}
+
+func (l FSLoader) Resolve(from, name string) (string, error) {
+	if from != "" {
+		name = path.Join(path.Dir(from), name)
+	}
+	name = path.Clean(name)
+	if !fs.ValidPath(name) {
+		return "", fmt.Errorf("invalid module path %q", name)
+	}
+	return name, nil
+}
+
+func (l FSLoader) Load(name string) (string, error) {
+	bytes, err := fs.ReadFile(l.FS, name)
+	return string(bytes), err
+}
+
+// SetModuleLoader sets where import statements load modules from. It
+// defaults to an OSLoader for the current directory.
+func (i *Interpreter) SetModuleLoader(loader ModuleLoader) {
+	i.loader = loader
+}
+
+// DefineHostModule makes members importable as `import "host:name";`, which
+// binds name to an object whose properties are the members.
+func (i *Interpreter) DefineHostModule(name string, members map[string]interface{}) {
+	module := &hostModule{name, make(map[string]interface{})}
+	for member, value := range members {
+		if value != nil {
+			value, _ = i.fromGo(reflect.ValueOf(value))
+		}
+		module.members[member] = value
+	}
+	i.hostModules[name] = module
+}
+
+// Modules returns the canonical names of the modules imported so far, in
+// sorted order. Host modules aren't included.
+func (i *Interpreter) Modules() []string {
+	names := make([]string, 0, len(i.modules))
+	for name := range i.modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (i *Interpreter) VisitImportStmt(stmt Import) (interface{}, error) {
+	importPath := stmt.path.literal.(string)
+
+	if strings.HasPrefix(importPath, hostPrefix) {
+		name := strings.TrimPrefix(importPath, hostPrefix)
+		module, ok := i.hostModules[name]
+		if !ok {
+			return nil, RuntimeError{stmt.path, fmt.Sprintf("no host module %q", name)}
+		}
+		i.environment.define(name, module)
+		return nil, nil
+	}
+
+	name, err := i.loader.Resolve(i.module, importPath)
+	if err != nil {
+		return nil, RuntimeError{stmt.path, fmt.Sprintf("can't import %q: %v", importPath, err)}
+	}
+	if i.modules[name] {
+		// Already imported, or being imported further up an import cycle.
+		return nil, nil
+	}
+	i.modules[name] = true
+
+	// A module that fails to import can be imported again once fixed.
+	if err := i.importModule(stmt, name); err != nil {
+		delete(i.modules, name)
+		return nil, err
+	}
+	return nil, nil
+}
+
+// importModule loads, compiles and runs the module with canonical name name.
+func (i *Interpreter) importModule(stmt Import, name string) error {
+	importPath := stmt.path.literal.(string)
+	source, err := i.loader.Load(name)
+	if err != nil {
+		return RuntimeError{stmt.path, fmt.Sprintf("can't import %q: %v", importPath, err)}
+	}
+
+	program, err := Compile(source)
+	if err != nil {
+		return RuntimeError{stmt.path, fmt.Sprintf("in module %q:\n%v", importPath, err)}
+	}
+
+	return i.runModule(name, program)
+}
+
+// runModule executes a module's top level in the global scope.
+func (i *Interpreter) runModule(name string, program *Program) error {
+	previousModule, previousProgram := i.module, i.program
+	i.module, i.program = name, program
+	defer func() {
+		i.module, i.program = previousModule, previousProgram
+	}()
+
+	return i.executeBlock(program.stmts, i.globals)
+}
+
+// hostModule is a module provided by the host program. Scripts see it as an
+// object with read-only properties.
+type hostModule struct {
+	name    string
+	members map[string]interface{}
+}
+
+func (m *hostModule) Get(name Token) (interface{}, error) {
+	value, ok := m.members[name.lexeme]
+	if !ok {
+		return nil, RuntimeError{
+			name,
+			fmt.Sprintf("module %s has no member '%s'", m.name, name.lexeme),
+		}
+	}
+	return value, nil
+}
+
+func (m *hostModule) Set(name Token, value interface{}) error {
+	return RuntimeError{name, fmt.Sprintf("can't assign to module %s", m.name)}
+}
+
+func (m *hostModule) String() string {
+	return fmt.Sprintf("<module %s>", m.name)
+}
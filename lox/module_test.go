@@ -0,0 +1,125 @@
+package lox
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFSLoader(t *testing.T) {
+	fsys := fstest.MapFS{
+		"main.lox":      {Data: []byte(`import "lib/greet.lox"; import "lib/greet.lox"; greet("fs");`)},
+		"lib/greet.lox": {Data: []byte(`import "names.lox"; fun greet(who) { print prefix + who; }`)},
+		"lib/names.lox": {Data: []byte(`print "loading names"; var prefix = "hello ";`)},
+	}
+
+	stdout := &bytes.Buffer{}
+	interpreter := NewInterpreter()
+	interpreter.SetStdout(stdout)
+	interpreter.SetModuleLoader(FSLoader{fsys})
+
+	main, _ := fsys.ReadFile("main.lox")
+	if err := interpret(interpreter, string(main)); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	if want := "loading names\nhello fs\n"; stdout.String() != want {
+		t.Errorf("want output %q, got %q", want, stdout.String())
+	}
+	if want := []string{"lib/greet.lox", "lib/names.lox"}; !reflect.DeepEqual(interpreter.Modules(), want) {
+		t.Errorf("want modules %v, got %v", want, interpreter.Modules())
+	}
+}
+
+func TestOSLoader(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "lib.lox"), []byte(`var answer = 42;`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := &bytes.Buffer{}
+	interpreter := NewInterpreter()
+	interpreter.SetStdout(stdout)
+	interpreter.SetModuleLoader(OSLoader{Dir: dir})
+
+	if err := interpret(interpreter, `import "lib.lox"; print answer;`); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if want := "42\n"; stdout.String() != want {
+		t.Errorf("want output %q, got %q", want, stdout.String())
+	}
+}
+
+func TestImportRetry(t *testing.T) {
+	fsys := fstest.MapFS{
+		"lib.lox": {Data: []byte(`var answer = ;`)},
+	}
+
+	stdout := &bytes.Buffer{}
+	interpreter := NewInterpreter()
+	interpreter.SetStdout(stdout)
+	interpreter.SetModuleLoader(FSLoader{fsys})
+
+	if err := interpret(interpreter, `import "lib.lox";`); err == nil {
+		t.Fatal("want error importing a broken module, got none")
+	}
+	if len(interpreter.Modules()) != 0 {
+		t.Errorf("want no modules after a failed import, got %v", interpreter.Modules())
+	}
+
+	fsys["lib.lox"] = &fstest.MapFile{Data: []byte(`var answer = 42;`)}
+	if err := interpret(interpreter, `import "lib.lox"; print answer;`); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if want := "42\n"; stdout.String() != want {
+		t.Errorf("want output %q, got %q", want, stdout.String())
+	}
+}
+
+func TestHostModule(t *testing.T) {
+	var counted []string
+
+	interpreter := NewInterpreter()
+	interpreter.DefineHostModule("metrics", map[string]interface{}{
+		"count":  func(name string) { counted = append(counted, name) },
+		"prefix": "app.",
+	})
+
+	if err := interpret(interpreter, `import "host:metrics"; metrics.count(metrics.prefix + "runs");`); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if len(counted) != 1 || counted[0] != "app.runs" {
+		t.Errorf("want [app.runs], got %v", counted)
+	}
+
+	// A fresh interpreter, so the import above can't leave a global behind.
+	stdout := &bytes.Buffer{}
+	scoped := NewInterpreter()
+	scoped.SetStdout(stdout)
+	scoped.DefineHostModule("metrics", map[string]interface{}{"prefix": "app."})
+	if err := interpret(scoped, `{ import "host:metrics"; print metrics.prefix; }`); err != nil {
+		t.Fatalf("want no error importing in a block, got %v", err)
+	}
+	if want := "app.\n"; stdout.String() != want {
+		t.Errorf("want output %q, got %q", want, stdout.String())
+	}
+
+	cases := []string{
+		`import "host:missing";`,
+		`import "host:metrics"; metrics.prefix = "x";`,
+		`import "host:metrics"; metrics.nope;`,
+		`import "no/such/file.lox";`,
+	}
+	for _, source := range cases {
+		t.Run(source, func(t *testing.T) {
+			var runtimeError RuntimeError
+			if err := interpret(interpreter, source); !errors.As(err, &runtimeError) {
+				t.Errorf("want RuntimeError, got %v", err)
+			}
+		})
+	}
+}
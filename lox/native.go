@@ -0,0 +1,225 @@
+package lox
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+var (
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+	callableType = reflect.TypeOf((*LoxCallable)(nil)).Elem()
+	objectType   = reflect.TypeOf((*LoxObject)(nil)).Elem()
+)
+
+// native is a Go function made callable from Lox. Arguments and results are
+// converted between Lox and Go values by reflection.
+type native struct {
+	name string
+	fn   reflect.Value
+}
+
+// DefineNative binds name in the global scope to the Go function fn.
+//
+// Lox numbers convert to any Go integer or floating-point parameter, strings
+// to string, booleans to bool and nil to the zero value of pointer, slice,
+// map and interface parameters. Lox functions can be passed as LoxCallable
+// or interface{} parameters. fn may be variadic, and may return nothing, one
+// value, an error, or one value and an error; a non-nil error becomes a
+// RuntimeError at the call site, except an ExitError, which stops the script.
+func (i *Interpreter) DefineNative(name string, fn interface{}) error {
+	native, err := newNative(name, reflect.ValueOf(fn))
+	if err != nil {
+		return err
+	}
+
+	i.Define(name, native)
+	return nil
+}
+
+func newNative(name string, fn reflect.Value) (*native, error) {
+	if !fn.IsValid() {
+		return nil, fmt.Errorf("native %s: nil is not a function", name)
+	}
+	if fn.Kind() != reflect.Func || fn.IsNil() {
+		return nil, fmt.Errorf("native %s: %v is not a function", name, fn.Type())
+	}
+
+	t := fn.Type()
+	switch {
+	case t.NumOut() == 0:
+	case t.NumOut() == 1:
+	case t.NumOut() == 2 && t.Out(1) == errorType:
+	default:
+		return nil, fmt.Errorf("native %s: unsupported results in %v", name, t)
+	}
+
+	return &native{name, fn}, nil
+}
+
+// Arity is -1 for variadic functions, which check their argument count in
+// Call instead.
+func (n *native) Arity() int {
+	if n.fn.Type().IsVariadic() {
+		return -1
+	}
+	return n.fn.Type().NumIn()
+}
+
+func (n *native) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	t := n.fn.Type()
+	token := interpreter.callSite()
+
+	if t.IsVariadic() && len(arguments) < t.NumIn()-1 {
+		return nil, RuntimeError{
+			token,
+			fmt.Sprintf("expected at least %d arguments but got %d",
+				t.NumIn()-1, len(arguments)),
+		}
+	}
+
+	in := make([]reflect.Value, len(arguments))
+	for k, argument := range arguments {
+		var paramType reflect.Type
+		if t.IsVariadic() && k >= t.NumIn()-1 {
+			paramType = t.In(t.NumIn() - 1).Elem()
+		} else {
+			paramType = t.In(k)
+		}
+
+		value, err := toGo(argument, paramType)
+		if err != nil {
+			return nil, RuntimeError{
+				token,
+				fmt.Sprintf("argument %d to %s: %v", k+1, n.name, err),
+			}
+		}
+		in[k] = value
+	}
+
+	out := n.fn.Call(in)
+
+	if len(out) > 0 && t.Out(len(out)-1) == errorType {
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			if exit, ok := err.(ExitError); ok {
+				return nil, exit
+			}
+			return nil, RuntimeError{token, err.Error()}
+		}
+		out = out[:len(out)-1]
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+
+	result, err := interpreter.fromGo(out[0])
+	if err != nil {
+		return nil, RuntimeError{token, fmt.Sprintf("result of %s: %v", n.name, err)}
+	}
+	return result, nil
+}
+
+func (n *native) String() string {
+	return "<native fn>"
+}
+
+// toGo converts a Lox value to a Go value of type t.
+func toGo(value interface{}, t reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface, reflect.Func:
+			return reflect.Zero(t), nil
+		}
+		return reflect.Value{}, fmt.Errorf("can't use nil as %v", t)
+	}
+
+	if host, ok := value.(*HostObject); ok && host.value.Type().AssignableTo(t) {
+		return host.value, nil
+	}
+
+	v := reflect.ValueOf(value)
+	if num, ok := value.(float64); ok {
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if num != math.Trunc(num) {
+				return reflect.Value{}, fmt.Errorf("%v is not an integer", num)
+			}
+			converted := v.Convert(t)
+			if converted.Convert(v.Type()).Float() != num {
+				return reflect.Value{}, fmt.Errorf("%v overflows %v", num, t)
+			}
+			return converted, nil
+		case reflect.Float32, reflect.Float64:
+			return v.Convert(t), nil
+		}
+	}
+
+	if v.Type().AssignableTo(t) {
+		ret := reflect.New(t).Elem()
+		ret.Set(v)
+		return ret, nil
+	}
+	return reflect.Value{}, fmt.Errorf("can't use %v as %v", loxType(value), t)
+}
+
+// fromGo converts a Go value returned by a native to a Lox value. Structs
+// and pointers to structs become host objects.
+func (i *Interpreter) fromGo(v reflect.Value) (interface{}, error) {
+	if v.Type().Implements(callableType) || v.Type().Implements(objectType) {
+		return v.Interface(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		if v.Kind() == reflect.Interface {
+			return i.fromGo(v.Elem())
+		}
+		if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
+			return &HostObject{v, i}, nil
+		}
+	case reflect.Struct:
+		return &HostObject{v, i}, nil
+	case reflect.Func:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return newNative("<anonymous>", v)
+	}
+
+	return v.Interface(), nil
+}
+
+// loxType names the Lox type of value for error messages.
+func loxType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case LoxCallable:
+		return "function"
+	case *HostObject:
+		return "object"
+	case *List:
+		return "list"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,83 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDefineNative(t *testing.T) {
+	interpreter := NewInterpreter()
+
+	var got []interface{}
+	natives := map[string]interface{}{
+		"record": func(v interface{}) { got = append(got, v) },
+		"add":    func(a, b int) int { return a + b },
+		"join":   func(sep string, parts ...string) string { return strings.Join(parts, sep) },
+		"half":   func(x float64) (float64, error) { return x / 2, nil },
+		"apply": func(f LoxCallable) (interface{}, error) {
+			return f.Call(interpreter, []interface{}{1.0})
+		},
+	}
+	for name, fn := range natives {
+		if err := interpreter.DefineNative(name, fn); err != nil {
+			t.Fatalf("want no error, got %v", err)
+		}
+	}
+
+	source := `
+record(add(1, 2));
+record(join("-", "a", "b", "c"));
+record(half(3));
+fun inc(n) { return n + 1; }
+record(apply(inc));
+record(nil);
+`
+	if err := interpret(interpreter, source); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	want := []interface{}{3.0, "a-b-c", 1.5, 2.0, nil}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestDefineNativeErrors(t *testing.T) {
+	interpreter := NewInterpreter()
+	interpreter.DefineNative("add", func(a, b int) int { return a + b })
+	interpreter.DefineNative("fail", func() error { return errors.New("failed") })
+	interpreter.DefineNative("join", func(sep string, parts ...string) string { return "" })
+
+	cases := []struct {
+		source  string
+		message string
+	}{
+		{`add(1, "2");`, "argument 2 to add: can't use string as int"},
+		{`add(1.5, 2);`, "argument 1 to add: 1.5 is not an integer"},
+		{`fail();`, "failed"},
+		{`join();`, "expected at least 1 arguments but got 0"},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.source, func(t *testing.T) {
+			err := interpret(interpreter, cc.source)
+
+			var runtimeError RuntimeError
+			if !errors.As(err, &runtimeError) {
+				t.Fatalf("want RuntimeError, got %v", err)
+			}
+			if runtimeError.message != cc.message {
+				t.Errorf("want message %q, got %q", cc.message, runtimeError.message)
+			}
+		})
+	}
+
+	if err := interpreter.DefineNative("bad", 42); err == nil {
+		t.Error("want error defining a non-function, got none")
+	}
+	if err := interpreter.DefineNative("bad", nil); err == nil {
+		t.Error("want error defining nil, got none")
+	}
+}
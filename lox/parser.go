@@ -1,9 +1,8 @@
-package main
+package lox
 
 import (
 	"errors"
 	"fmt"
-	"log"
 )
 
 var (
@@ -13,28 +12,108 @@ var (
 type Parser struct {
 	tokens  []Token
 	current int
+	errors  []StaticError
+
+	// echo lets the last statement be an expression without a ';', which
+	// is printed, as the REPL wants.
+	echo bool
+}
+
+// Parse parses tokens into a program. After a syntax error parsing resumes at
+// the next statement; if there were any errors, they are returned as
+// StaticErrors wrapping ErrParse.
+func Parse(tokens []Token) ([]Stmt, error) {
+	parser := Parser{tokens: tokens}
+	stmts := parser.Parse()
+	if err := staticErrors(ErrParse, parser.errors); err != nil {
+		return nil, err
+	}
+	return stmts, nil
+}
+
+// ParseREPL is like Parse but, as for a line typed at a prompt, accepts a
+// trailing expression without a ';' and prints its value.
+func ParseREPL(tokens []Token) ([]Stmt, error) {
+	parser := Parser{tokens: tokens, echo: true}
+	stmts := parser.Parse()
+	if err := staticErrors(ErrParse, parser.errors); err != nil {
+		return nil, err
+	}
+	return stmts, nil
+}
+
+// ParseExpression parses tokens as a single expression, which must make up
+// all of the input. Errors are returned as StaticErrors wrapping ErrParse.
+func ParseExpression(tokens []Token) (Expr, error) {
+	parser := Parser{tokens: tokens}
+	expr := parser.wholeExpression()
+	if err := staticErrors(ErrParse, parser.errors); err != nil {
+		return nil, err
+	}
+	return expr, nil
+}
+
+func (p *Parser) wholeExpression() (expr Expr) {
+	defer func() {
+		if r := recover(); r != nil {
+			if r != ErrParse {
+				panic(r)
+			}
+			expr = nil
+		}
+	}()
+
+	expr = p.expression()
+	if !p.isAtEnd() {
+		p.parseError(p.peek(), "expect end of expression")
+	}
+	return expr
 }
 
 func (p *Parser) Parse() []Stmt {
 	var statements []Stmt
 	for !p.isAtEnd() {
-		statements = append(statements, p.declaration())
+		if stmt := p.declaration(); stmt != nil {
+			statements = append(statements, stmt)
+		}
 	}
 	return statements
 }
 
-func (p *Parser) declaration() Stmt {
+func (p *Parser) declaration() (stmt Stmt) {
+	// Syntax errors unwind to here as ErrParse panics so that the parser can
+	// skip to the next statement and keep looking for errors.
+	defer func() {
+		if r := recover(); r != nil {
+			if r != ErrParse {
+				panic(r)
+			}
+			p.synchronize()
+			stmt = nil
+		}
+	}()
+
 	if p.match(FUN) {
 		return p.function("function")
 	}
 	if p.match(VAR) {
 		return p.varDeclaration()
 	}
+	if p.match(IMPORT) {
+		return p.importDeclaration()
+	}
 	return p.statement()
 }
 
+func (p *Parser) importDeclaration() Stmt {
+	keyword := p.previous()
+	path := p.consume(STRING, "expect module path after 'import'")
+	p.consume(SEMICOLON, "expect ';' after module path")
+	return Import{keyword, path}
+}
+
 func (p *Parser) varDeclaration() Stmt {
-	name, _ := p.consume(IDENTIFIER, "expect variable name.")
+	name := p.consume(IDENTIFIER, "expect variable name.")
 
 	var initializer *Expr
 	if p.match(EQUAL) {
@@ -47,18 +126,17 @@ func (p *Parser) varDeclaration() Stmt {
 }
 
 func (p *Parser) function(kind string) Function {
-	name, _ := p.consume(IDENTIFIER, fmt.Sprintf("expect %s name", kind))
+	name := p.consume(IDENTIFIER, fmt.Sprintf("expect %s name", kind))
 	p.consume(LEFT_PAREN, fmt.Sprintf("expect '(' after %s name", kind))
 
 	var parameters []Token
 	if !p.check(RIGHT_PAREN) {
 		for {
 			if len(parameters) >= 255 {
-				// todo: error
-				panic("can't have more than 255 parameters")
+				p.parseError(p.peek(), "can't have more than 255 parameters")
 			}
 
-			param, _ := p.consume(IDENTIFIER, "expect parameter name")
+			param := p.consume(IDENTIFIER, "expect parameter name")
 			parameters = append(parameters, param)
 
 			if !p.match(COMMA) {
@@ -96,7 +174,8 @@ func (p *Parser) statement() Stmt {
 	}
 
 	if p.match(LEFT_BRACE) {
-		return Block{p.block()}
+		brace := p.previous()
+		return Block{brace, p.block()}
 	}
 
 	return p.expressionStatement()
@@ -122,6 +201,9 @@ func (p *Parser) returnStatement() Stmt {
 
 func (p *Parser) expressionStatement() Stmt {
 	expr := p.expression()
+	if p.echo && p.isAtEnd() {
+		return Echo{expr}
+	}
 	p.consume(SEMICOLON, "Expect ';' after expression.")
 	return Expression{expr}
 }
@@ -151,6 +233,8 @@ func (p *Parser) whileStatement() Stmt {
 }
 
 func (p *Parser) forStatement() Stmt {
+	// The blocks a for loop is desugared into have no brace of their own.
+	keyword := p.previous()
 	p.consume(LEFT_PAREN, "expect '(' after 'for'")
 
 	var initializer *Stmt
@@ -181,7 +265,7 @@ func (p *Parser) forStatement() Stmt {
 	body := p.statement()
 
 	if increment != nil {
-		body = Block{[]Stmt{body, Expression{*increment}}}
+		body = Block{keyword, []Stmt{body, Expression{*increment}}}
 	}
 
 	if condition == nil {
@@ -191,7 +275,7 @@ func (p *Parser) forStatement() Stmt {
 	body = While{*condition, body}
 
 	if initializer != nil {
-		body = Block{[]Stmt{*initializer, body}}
+		body = Block{keyword, []Stmt{*initializer, body}}
 	}
 
 	return body
@@ -201,7 +285,9 @@ func (p *Parser) block() []Stmt {
 	statements := []Stmt{}
 
 	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
-		statements = append(statements, p.declaration())
+		if stmt := p.declaration(); stmt != nil {
+			statements = append(statements, stmt)
+		}
 	}
 
 	p.consume(RIGHT_BRACE, "expect '}' after block")
@@ -227,8 +313,11 @@ func (p *Parser) assignment() Expr {
 			name := v.name
 			return Assign{name, value}
 		}
+		if get, ok := expr.(Get); ok {
+			return Set{get.object, get.name, value}
+		}
 
-		log.Printf("Invalid assignment target %v", equals)
+		p.parseError(equals, "invalid assignment target")
 	}
 
 	return expr
@@ -322,6 +411,9 @@ func (p *Parser) call() Expr {
 	for {
 		if p.match(LEFT_PAREN) {
 			expr = p.finishCall(expr)
+		} else if p.match(DOT) {
+			name := p.consume(IDENTIFIER, "expect property name after '.'")
+			expr = Get{expr, name}
 		} else {
 			break
 		}
@@ -335,8 +427,7 @@ func (p *Parser) finishCall(callee Expr) Expr {
 	if !p.check(RIGHT_PAREN) {
 		for {
 			if len(arguments) >= 255 {
-				// todo: no panic
-				panic("Can't have more than 255 arguments")
+				p.parseError(p.peek(), "can't have more than 255 arguments")
 			}
 
 			arguments = append(arguments, p.expression())
@@ -347,7 +438,7 @@ func (p *Parser) finishCall(callee Expr) Expr {
 		}
 	}
 
-	paren, _ := p.consume(RIGHT_PAREN, "expect ')' after arguments")
+	paren := p.consume(RIGHT_PAREN, "expect ')' after arguments")
 
 	return Call{callee, paren, arguments}
 }
@@ -370,7 +461,7 @@ func (p *Parser) primary() Expr {
 		return Grouping{expr}
 	}
 
-	panic("Expect expression.")
+	panic(p.parseError(p.peek(), "Expect expression."))
 }
 
 func (p *Parser) match(types ...TokenType) bool {
@@ -410,32 +501,36 @@ func (p *Parser) previous() Token {
 	return p.tokens[p.current-1]
 }
 
-func (p *Parser) consume(ttype TokenType, message string) (Token, error) {
+// consume advances past the expected token, or abandons the current
+// statement with a syntax error.
+func (p *Parser) consume(ttype TokenType, message string) Token {
 	if p.check(ttype) {
-		return p.advance(), nil
+		return p.advance()
 	}
 
-	return Token{}, p.parseError(p.peek(), message)
+	panic(p.parseError(p.peek(), message))
 }
 
+// parseError records a syntax error. Errors the parser can't recover from
+// within the current statement are raised by panicking with the result.
 func (p *Parser) parseError(token Token, message string) error {
-	ReportError(token, message)
+	p.errors = append(p.errors, newTokenError(token, message))
 	return ErrParse
 }
 
-//func (p *Parser) synchronize() {
-//	p.advance()
-//
-//	for !p.isAtEnd() {
-//		if p.previous().Ttype == SEMICOLON {
-//			return
-//		}
-//
-//		switch p.peek().Ttype {
-//		case CLASS, FUN, VAR, FOR, IF, WHILE, PRINT, RETURN:
-//			return
-//		}
-//
-//		p.advance()
-//	}
-//}
+func (p *Parser) synchronize() {
+	p.advance()
+
+	for !p.isAtEnd() {
+		if p.previous().ttype == SEMICOLON {
+			return
+		}
+
+		switch p.peek().ttype {
+		case CLASS, FUN, VAR, FOR, IF, IMPORT, WHILE, PRINT, RETURN:
+			return
+		}
+
+		p.advance()
+	}
+}
@@ -0,0 +1,50 @@
+package lox
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParser(t *testing.T) {
+	tokens, err := Scan("var a = 1; print a;")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	stmts, err := Parse(tokens)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if len(stmts) != 2 {
+		t.Errorf("want %d statements, got %d", 2, len(stmts))
+	}
+}
+
+func TestParseError(t *testing.T) {
+	cases := []string{
+		"print ;",
+		"var = 1;",
+		"{ print 1; ",
+		"1 = 2;",
+	}
+
+	for _, source := range cases {
+		t.Run(source, func(t *testing.T) {
+			tokens, _ := Scan(source)
+			if _, err := Parse(tokens); !errors.Is(err, ErrParse) {
+				t.Errorf("want error %v, got %v", ErrParse, err)
+			}
+		})
+	}
+}
+
+func TestParseErrorMessages(t *testing.T) {
+	tokens, _ := Scan("print ;\nvar = 1;")
+	_, err := Parse(tokens)
+
+	want := "[line 1] Error at ';': Expect expression.\n" +
+		"[line 2] Error at '=': expect variable name."
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
@@ -0,0 +1,67 @@
+package lox
+
+import "context"
+
+// Program is a script that has been scanned, parsed and resolved, ready to
+// run any number of times. A Program is never modified after Compile
+// returns, so one can be shared by interpreters in different goroutines.
+type Program struct {
+	source string
+	repl   bool // compiled by CompileREPL
+	stmts  []Stmt
+	locals map[Expr]int
+}
+
+// Compile runs the front end over source. If scanning fails the parser is
+// not run, so only the scan errors are returned.
+func Compile(source string) (*Program, error) {
+	return compile(source, false)
+}
+
+// CompileREPL is like Compile but parses with ParseREPL, so a trailing
+// expression without a ';' prints its value.
+func CompileREPL(source string) (*Program, error) {
+	return compile(source, true)
+}
+
+func compile(source string, repl bool) (*Program, error) {
+	tokens, err := Scan(source)
+	if err != nil {
+		return nil, err
+	}
+
+	parse := Parse
+	if repl {
+		parse = ParseREPL
+	}
+	stmts, err := parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	locals := make(map[Expr]int)
+	resolver := NewResolver(locals)
+	resolver.resolveStmts(stmts)
+	if err := staticErrors(ErrResolve, resolver.errors); err != nil {
+		return nil, err
+	}
+
+	return &Program{source, repl, stmts, locals}, nil
+}
+
+// Run executes program in this interpreter's global scope, so it sees any
+// globals that were defined beforehand.
+func (i *Interpreter) Run(program *Program) error {
+	return i.RunContext(context.Background(), program)
+}
+
+// RunContext is like Run but gives up when ctx is done.
+func (i *Interpreter) RunContext(ctx context.Context, program *Program) error {
+	previous := i.program
+	i.program = program
+	defer func() {
+		i.program = previous
+	}()
+
+	return i.InterpretContext(ctx, program.stmts)
+}
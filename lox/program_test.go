@@ -0,0 +1,123 @@
+package lox
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestProgram(t *testing.T) {
+	program, err := Compile(`
+fun total(price, qty) {
+    var sum = price * qty;
+    return sum;
+}
+print total(price, qty) > 100;
+`)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for n := 0; n < 20; n++ {
+		wg.Add(1)
+		go func(qty int) {
+			defer wg.Done()
+
+			stdout := &bytes.Buffer{}
+			interpreter := NewInterpreter()
+			interpreter.SetStdout(stdout)
+			interpreter.Define("price", 10)
+			interpreter.Define("qty", qty)
+
+			if err := interpreter.Run(program); err != nil {
+				t.Errorf("want no error, got %v", err)
+			}
+			if want := fmt.Sprintln(qty*10 > 100); stdout.String() != want {
+				t.Errorf("qty %d: want output %q, got %q", qty, want, stdout.String())
+			}
+		}(n)
+	}
+	wg.Wait()
+}
+
+func TestProgramClosuresOutliveRun(t *testing.T) {
+	interpreter := NewInterpreter()
+
+	program, _ := Compile(`
+fun makeCounter() {
+    var i = 0;
+    fun count() {
+        i = i + 1;
+        return i;
+    }
+    return count;
+}
+var counter = makeCounter();
+`)
+	if err := interpreter.Run(program); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	// Running more code must not disturb the resolution of counter's body.
+	other, _ := Compile("{ var i = 10; { i = i + 1; } }")
+	if err := interpreter.Run(other); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	counter, _ := interpreter.Callback("counter")
+	counter.Call()
+	got, err := counter.Call()
+	if err != nil || got != 2.0 {
+		t.Errorf("want 2, got %v (%v)", got, err)
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	if _, err := Compile("print ;"); !errors.Is(err, ErrParse) {
+		t.Errorf("want error %v, got %v", ErrParse, err)
+	}
+	if _, err := Compile("{ var a = a; }"); !errors.Is(err, ErrResolve) {
+		t.Errorf("want error %v, got %v", ErrResolve, err)
+	}
+}
+
+func TestCompileREPL(t *testing.T) {
+	cases := []struct {
+		source string
+		want   string
+	}{
+		{"1 + 2", "3\n"},
+		{"1 + 2;", ""},
+		{"var a = 1; a + 1", "2\n"},
+		{"nil", "nil\n"},
+		{"print nil;", "<nil>\n"},
+		{`"a" + "b"`, "ab\n"},
+		{"print true;", "true\n"},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.source, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			interpreter := NewInterpreter()
+			interpreter.SetStdout(stdout)
+
+			program, err := CompileREPL(cc.source)
+			if err != nil {
+				t.Fatalf("want no error, got %v", err)
+			}
+			if err := interpreter.Run(program); err != nil {
+				t.Fatalf("want no error, got %v", err)
+			}
+			if stdout.String() != cc.want {
+				t.Errorf("want output %q, got %q", cc.want, stdout.String())
+			}
+		})
+	}
+
+	if _, err := Compile("1 + 2"); !errors.Is(err, ErrParse) {
+		t.Errorf("Compile: want ErrParse, got %v", err)
+	}
+}
@@ -1,19 +1,42 @@
-package main
+package lox
 
-import "fmt"
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrResolve = errors.New("resolve error")
+)
 
 type scope map[string]bool
 
 type Resolver struct {
-	interpreter *Interpreter
-	scopes      []scope
+	locals map[Expr]int
+	scopes []scope
+	errors []StaticError
+
+	// globals, if set, holds every global variable name that may be used, so
+	// that references to any other are reported before running.
+	globals map[string]interface{}
+}
+
+// Resolve works out which scope each variable in stmts refers to and records
+// it in interpreter, which must be the one that will run stmts. Any errors
+// are returned as StaticErrors wrapping ErrResolve.
+func Resolve(interpreter *Interpreter, stmts []Stmt) error {
+	resolver := NewResolver(interpreter.program.locals)
+	resolver.resolveStmts(stmts)
+	return staticErrors(ErrResolve, resolver.errors)
 }
 
-func NewResolver(interpreter *Interpreter) Resolver {
+// NewResolver returns a resolver that records how many scopes away each
+// local variable is defined in locals.
+func NewResolver(locals map[Expr]int) Resolver {
 	var scopes []scope
 	return Resolver{
-		interpreter,
-		scopes,
+		locals: locals,
+		scopes: scopes,
 	}
 }
 
@@ -78,23 +101,28 @@ func (r *Resolver) VisitVariableExpr(expr Variable) (interface{}, error) {
 	if len(r.scopes) != 0 {
 		scope := r.scopes[len(r.scopes)-1]
 		if val, ok := scope[expr.name.lexeme]; ok && !val {
-			//fmt.Printf("scope: %+v\n", scope)
-			//fmt.Printf("r.scopes: %+v\n", r.scopes)
-			panic(fmt.Sprintf("can't read local variable in its own initializer: %q",
-				expr.name.lexeme))
+			r.error(expr.name, "can't read local variable in its own initializer")
 		}
 	}
 	r.resolveLocal(expr, expr.name)
 	return nil, nil
 }
 
+func (r *Resolver) error(token Token, message string) {
+	r.errors = append(r.errors, newTokenError(token, message))
+}
+
 func (r *Resolver) resolveLocal(expr Expr, name Token) {
 	for i := len(r.scopes) - 1; i >= 0; i-- {
 		if _, ok := r.scopes[i][name.lexeme]; ok {
-			r.interpreter.resolve(expr, len(r.scopes)-1-i)
+			r.locals[expr] = len(r.scopes) - 1 - i
 			return
 		}
 	}
+
+	if _, ok := r.globals[name.lexeme]; r.globals != nil && !ok {
+		r.error(name, "undefined variable")
+	}
 }
 
 func (r *Resolver) VisitAssignExpr(expr Assign) (interface{}, error) {
@@ -134,11 +162,28 @@ func (r *Resolver) VisitIfStmt(stmt If) (interface{}, error) {
 	return nil, nil
 }
 
+func (r *Resolver) VisitImportStmt(stmt Import) (interface{}, error) {
+	// Source modules are resolved on their own when they are loaded and
+	// define their globals there; host modules bind their name right here.
+	if importPath := stmt.path.literal.(string); strings.HasPrefix(importPath, hostPrefix) {
+		name := stmt.path
+		name.lexeme = strings.TrimPrefix(importPath, hostPrefix)
+		r.declare(name)
+		r.define(name)
+	}
+	return nil, nil
+}
+
 func (r *Resolver) VisitPrintStmt(stmt Print) (interface{}, error) {
 	r.resolveExpr(stmt.expression)
 	return nil, nil
 }
 
+func (r *Resolver) VisitEchoStmt(stmt Echo) (interface{}, error) {
+	r.resolveExpr(stmt.expression)
+	return nil, nil
+}
+
 func (r *Resolver) VisitReturnStmt(stmt Return) (interface{}, error) {
 	if stmt.value != nil {
 		r.resolveExpr(*stmt.value)
@@ -166,6 +211,11 @@ func (r *Resolver) VisitCallExpr(expr Call) (interface{}, error) {
 	return nil, nil
 }
 
+func (r *Resolver) VisitGetExpr(expr Get) (interface{}, error) {
+	r.resolveExpr(expr.object)
+	return nil, nil
+}
+
 func (r *Resolver) VisitGroupingExpr(expr Grouping) (interface{}, error) {
 	r.resolveExpr(expr.expression)
 	return nil, nil
@@ -181,6 +231,12 @@ func (r *Resolver) VisitLogicalExpr(expr Logical) (interface{}, error) {
 	return nil, nil
 }
 
+func (r *Resolver) VisitSetExpr(expr Set) (interface{}, error) {
+	r.resolveExpr(expr.value)
+	r.resolveExpr(expr.object)
+	return nil, nil
+}
+
 func (r *Resolver) VisitUnaryExpr(expr Unary) (interface{}, error) {
 	r.resolveExpr(expr.right)
 	return nil, nil
@@ -0,0 +1,47 @@
+package lox
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ErrOperandMustBeANumber     = "operand must be a number"
+	ErrOperandsMustBeNumbers    = "operands must be numbers"
+	ErrOperandsMustBeNumsOrStrs = "operands must be two numbers or two strings"
+	ErrMemoryLimitExceeded      = "memory limit exceeded"
+)
+
+type RuntimeError struct {
+	token   Token
+	message string
+}
+
+func (e RuntimeError) Error() string {
+	return fmt.Sprintf("%s\n[line %d]", e.message, e.token.line)
+}
+
+// StackOverflowError is the RuntimeError raised when calls nest deeper than
+// the interpreter allows. It carries the call stack at the point of overflow.
+type StackOverflowError struct {
+	RuntimeError
+	trace []string
+}
+
+func (e StackOverflowError) Error() string {
+	return e.RuntimeError.Error() + "\n" + strings.Join(e.trace, "\n")
+}
+
+func (e StackOverflowError) Unwrap() error {
+	return e.RuntimeError
+}
+
+// ExitError is returned by Interpret when a script calls exit(code). A native
+// can return one too, to stop the script the same way.
+type ExitError struct {
+	Code int
+}
+
+func (e ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
@@ -1,7 +1,14 @@
-package main
+package lox
 
 import (
+	"errors"
+	"sort"
 	"strconv"
+	"strings"
+)
+
+var (
+	ErrScan = errors.New("scan error")
 )
 
 type Scanner struct {
@@ -13,6 +20,22 @@ type Scanner struct {
 	line    int
 
 	keywords map[string]TokenType
+	errors   []StaticError
+
+	// openString is set when the source ends inside a string literal.
+	openString bool
+
+	// keepComments makes comments COMMENT tokens rather than skipping them.
+	keepComments bool
+}
+
+// Scan splits source into tokens. Scanning carries on past lexical errors;
+// if there were any, they are returned as StaticErrors wrapping ErrScan
+// alongside the tokens that could be scanned.
+func Scan(source string) ([]Token, error) {
+	scanner := NewScanner(source)
+	tokens := scanner.ScanTokens()
+	return tokens, staticErrors(ErrScan, scanner.errors)
 }
 
 func NewScanner(source string) *Scanner {
@@ -26,6 +49,16 @@ func NewScanner(source string) *Scanner {
 	}
 }
 
+// Keywords returns Lox's reserved words in sorted order.
+func Keywords() []string {
+	var words []string
+	for word := range newKeywords() {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}
+
 func newKeywords() map[string]TokenType {
 	var ks = make(map[string]TokenType)
 
@@ -36,6 +69,7 @@ func newKeywords() map[string]TokenType {
 	ks["for"] = FOR
 	ks["fun"] = FUN
 	ks["if"] = IF
+	ks["import"] = IMPORT
 	ks["nil"] = NIL
 	ks["or"] = OR
 	ks["print"] = PRINT
@@ -50,12 +84,25 @@ func newKeywords() map[string]TokenType {
 }
 
 func (s *Scanner) ScanTokens() []Token {
+	// A "#!" line lets scripts be run directly; skip it like a comment,
+	// leaving its newline to be counted.
+	if s.current == 0 && strings.HasPrefix(s.source, "#!") {
+		for s.peek() != "\n" && !s.isAtEnd() {
+			s.advance()
+		}
+		if s.keepComments {
+			s.addToken(COMMENT, nil)
+		}
+	}
+
 	for !s.isAtEnd() {
 		s.start = s.current
 		s.scanToken()
 	}
 
-	s.tokens = append(s.tokens, NewToken(EOF, "", "", s.line))
+	eof := NewToken(EOF, "", "", s.line)
+	eof.offset = len(s.source)
+	s.tokens = append(s.tokens, eof)
 	return s.tokens
 }
 
@@ -112,6 +159,9 @@ func (s *Scanner) scanToken() {
 			for s.peek() != "\n" && !s.isAtEnd() {
 				s.advance()
 			}
+			if s.keepComments {
+				s.addToken(COMMENT, nil)
+			}
 		} else {
 			s.addToken(SLASH, "/")
 		}
@@ -126,11 +176,15 @@ func (s *Scanner) scanToken() {
 		} else if isAlpha(c) {
 			s.identifier()
 		} else {
-			ErrorReport(s.line, "Unexpected character.")
+			s.error("Unexpected character.")
 		}
 	}
 }
 
+func (s *Scanner) error(message string) {
+	s.errors = append(s.errors, newStaticError(s.line, message))
+}
+
 func (s *Scanner) advance() string {
 	c := s.source[s.current : s.current+1]
 	s.current++
@@ -139,7 +193,9 @@ func (s *Scanner) advance() string {
 
 func (s *Scanner) addToken(ttype TokenType, literal interface{}) {
 	lexeme := s.source[s.start:s.current]
-	s.tokens = append(s.tokens, NewToken(ttype, lexeme, literal, s.line))
+	token := NewToken(ttype, lexeme, literal, s.line)
+	token.offset = s.start
+	s.tokens = append(s.tokens, token)
 }
 
 func (s *Scanner) match(expected string) bool {
@@ -181,7 +237,8 @@ func (s *Scanner) scanString() {
 	}
 
 	if s.isAtEnd() {
-		ErrorReport(s.line, "Unterminated string.")
+		s.error("Unterminated string.")
+		s.openString = true
 		return
 	}
 
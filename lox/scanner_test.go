@@ -1,4 +1,4 @@
-package main
+package lox
 
 import (
 	"fmt"
@@ -30,6 +30,20 @@ func TestScanner(t *testing.T) {
 	}
 }
 
+func TestShebang(t *testing.T) {
+	toks := NewScanner("#!/usr/bin/env glox\nprint 1;").ScanTokens()
+	assertTokenTypes(t, toks, PRINT, NUMBER, SEMICOLON, EOF)
+	if toks[0].line != 2 {
+		t.Errorf("line want 2 got %d", toks[0].line)
+	}
+
+	// Only a first line is skipped.
+	_, err := Scan("print 1;\n#!/usr/bin/env glox")
+	if err == nil {
+		t.Errorf("want an error for #! after the first line")
+	}
+}
+
 func TestScanString(t *testing.T) {
 	source := `"string"`
 	toks := NewScanner(source).ScanTokens()
@@ -123,3 +137,21 @@ func assertTokenTypes(t *testing.T, toks []Token, ttypes ...TokenType) {
 		}
 	}
 }
+
+func TestTokenString(t *testing.T) {
+	toks := NewScanner(`var s = "hi"; 1.5`).ScanTokens()
+	want := []string{
+		`var "var"`,
+		`IDENTIFIER "s"`,
+		`= "="`,
+		`STRING "\"hi\"" "hi"`,
+		`; ";"`,
+		`NUMBER "1.5" 1.5`,
+		`EOF`,
+	}
+	for k, tok := range toks {
+		if got := tok.String(); got != want[k] {
+			t.Errorf("token %d: want %s got %s", k, want[k], got)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package lox
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+const snapshotVersion = 1
+
+// snapshot is the saved form of an interpreter's globals. Environments and
+// functions refer to each other by index, which is how cycles, such as a
+// function captured by the environment it closes over, are stored.
+type snapshot struct {
+	Version int `json:"version"`
+
+	// Sources holds the source of every program that declared a saved
+	// function. Functions are rebuilt by compiling it again, with
+	// CompileREPL for the indexes listed in REPL.
+	Sources []string `json:"sources"`
+	REPL    []int    `json:"repl,omitempty"`
+
+	// Environments[0] is the global scope.
+	Environments []snapshotEnvironment `json:"environments"`
+	Functions    []snapshotFunction    `json:"functions"`
+}
+
+type snapshotEnvironment struct {
+	Enclosing int                      `json:"enclosing"` // -1 for none
+	Values    map[string]snapshotValue `json:"values"`
+}
+
+type snapshotFunction struct {
+	Source  int `json:"source"`
+	Offset  int `json:"offset"` // of the declaration's name
+	Closure int `json:"closure"`
+}
+
+type snapshotValue struct {
+	Type string `json:"type"`
+
+	Bool     bool   `json:"bool,omitempty"`
+	Number   string `json:"number,omitempty"`
+	String   string `json:"string,omitempty"`
+	Function int    `json:"function,omitempty"`
+
+	// Global names the global that an opaque value, such as a native, was
+	// bound to, or the host module that was imported. Restoring takes
+	// whatever is bound to that name then.
+	Global string `json:"global,omitempty"`
+}
+
+// Snapshot writes the interpreter's global variables to w, including
+// functions and the environments they have captured. Natives and other Go
+// values can't be saved themselves; they are recorded by the name of a
+// global they are bound to and re-bound by that name when restored.
+//
+// Functions are saved by their source, so only functions declared by a
+// Program from Compile or CompileREPL can be saved. Snapshot fails if a global
+// refers to one declared by statements passed straight to Interpret.
+func (i *Interpreter) Snapshot(w io.Writer) error {
+	s := snapshotter{
+		interpreter: i,
+		snapshot:    snapshot{Version: snapshotVersion},
+		sources:     make(map[*Program]int),
+		envs:        make(map[*Environment]int),
+		functions:   make(map[*LoxFunction]int),
+	}
+
+	if _, err := s.environment(i.globals); err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s.snapshot)
+}
+
+type snapshotter struct {
+	interpreter *Interpreter
+	snapshot    snapshot
+	sources     map[*Program]int
+	envs        map[*Environment]int
+	functions   map[*LoxFunction]int
+}
+
+func (s *snapshotter) environment(env *Environment) (int, error) {
+	if index, ok := s.envs[env]; ok {
+		return index, nil
+	}
+
+	// Claim an index before saving the values, which may lead back here.
+	index := len(s.snapshot.Environments)
+	s.envs[env] = index
+	s.snapshot.Environments = append(s.snapshot.Environments, snapshotEnvironment{
+		Enclosing: -1,
+		Values:    make(map[string]snapshotValue),
+	})
+
+	if env.enclosing != nil {
+		enclosing, err := s.environment(env.enclosing)
+		if err != nil {
+			return 0, err
+		}
+		s.snapshot.Environments[index].Enclosing = enclosing
+	}
+
+	// Visit names in order so that the same globals always save the same way.
+	names := make([]string, 0, len(env.values))
+	for name := range env.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		saved, err := s.value(env.values[name])
+		if err != nil {
+			return 0, fmt.Errorf("snapshot %s: %w", name, err)
+		}
+		s.snapshot.Environments[index].Values[name] = saved
+	}
+
+	return index, nil
+}
+
+func (s *snapshotter) function(f *LoxFunction) (int, error) {
+	if index, ok := s.functions[f]; ok {
+		return index, nil
+	}
+	if f.program == nil || f.program.source == "" {
+		return 0, fmt.Errorf("can't save %v: its source is unknown; run it with Compile and Run to save it", f)
+	}
+
+	source, ok := s.sources[f.program]
+	if !ok {
+		source = len(s.snapshot.Sources)
+		s.sources[f.program] = source
+		s.snapshot.Sources = append(s.snapshot.Sources, f.program.source)
+		if f.program.repl {
+			s.snapshot.REPL = append(s.snapshot.REPL, source)
+		}
+	}
+
+	index := len(s.snapshot.Functions)
+	s.functions[f] = index
+	s.snapshot.Functions = append(s.snapshot.Functions, snapshotFunction{
+		Source: source,
+		Offset: f.declaration.name.offset,
+	})
+
+	closure, err := s.environment(f.closure)
+	if err != nil {
+		return 0, err
+	}
+	s.snapshot.Functions[index].Closure = closure
+
+	return index, nil
+}
+
+func (s *snapshotter) value(value interface{}) (snapshotValue, error) {
+	switch v := value.(type) {
+	case nil:
+		return snapshotValue{Type: "nil"}, nil
+	case bool:
+		return snapshotValue{Type: "bool", Bool: v}, nil
+	case float64:
+		return snapshotValue{Type: "number", Number: strconv.FormatFloat(v, 'g', -1, 64)}, nil
+	case string:
+		return snapshotValue{Type: "string", String: v}, nil
+	case *LoxFunction:
+		index, err := s.function(v)
+		return snapshotValue{Type: "function", Function: index}, err
+	case *hostModule:
+		return snapshotValue{Type: "module", Global: v.name}, nil
+	}
+
+	name, ok := s.globalName(value)
+	if !ok {
+		return snapshotValue{}, fmt.Errorf("can't save %v: it isn't bound to a global", value)
+	}
+	return snapshotValue{Type: "global", Global: name}, nil
+}
+
+// globalName finds a global bound to value, preferring a native's own name.
+func (s *snapshotter) globalName(value interface{}) (string, bool) {
+	globals := s.interpreter.globals.values
+
+	if n, ok := value.(*native); ok && isEqual(globals[n.name], value) {
+		return n.name, true
+	}
+
+	var names []string
+	for name, global := range globals {
+		if isEqual(global, value) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "", false
+	}
+
+	sort.Strings(names)
+	return names[0], true
+}
+
+// Restore reads globals saved by Snapshot into this interpreter's global
+// scope. Globals holding natives or other Go values are bound to whatever
+// this interpreter's global of the same name is, so those must be defined
+// first.
+func (i *Interpreter) Restore(r io.Reader) error {
+	var saved snapshot
+	if err := json.NewDecoder(r).Decode(&saved); err != nil {
+		return fmt.Errorf("restore: %w", err)
+	}
+	if saved.Version != snapshotVersion {
+		return fmt.Errorf("restore: unsupported snapshot version %d", saved.Version)
+	}
+	if len(saved.Environments) == 0 {
+		return fmt.Errorf("restore: no global scope")
+	}
+
+	// Recreate every environment and function first, so that values can
+	// refer to any of them.
+	envs := make([]*Environment, len(saved.Environments))
+	envs[0] = i.globals
+	for index := 1; index < len(envs); index++ {
+		envs[index] = NewEnvironment(nil)
+	}
+	for index, env := range saved.Environments[1:] {
+		if env.Enclosing < 0 || env.Enclosing >= len(envs) {
+			return fmt.Errorf("restore: bad enclosing environment %d", env.Enclosing)
+		}
+		envs[index+1].enclosing = envs[env.Enclosing]
+	}
+
+	repl := make(map[int]bool)
+	for _, index := range saved.REPL {
+		repl[index] = true
+	}
+
+	programs := make([]*Program, len(saved.Sources))
+	declarations := make([]map[int]Function, len(saved.Sources))
+	for index, source := range saved.Sources {
+		program, err := compile(source, repl[index])
+		if err != nil {
+			return fmt.Errorf("restore: %w", err)
+		}
+		programs[index] = program
+		declarations[index] = make(map[int]Function)
+		collectFunctions(program.stmts, declarations[index])
+	}
+
+	functions := make([]*LoxFunction, len(saved.Functions))
+	for index, f := range saved.Functions {
+		if f.Source < 0 || f.Source >= len(programs) || f.Closure < 0 || f.Closure >= len(envs) {
+			return fmt.Errorf("restore: bad function %d", index)
+		}
+		declaration, ok := declarations[f.Source][f.Offset]
+		if !ok {
+			return fmt.Errorf("restore: no function at offset %d", f.Offset)
+		}
+		functions[index] = NewLoxFunction(declaration, envs[f.Closure], programs[f.Source])
+	}
+
+	for index, env := range saved.Environments {
+		for name, value := range env.Values {
+			if index == 0 && value.Type == "global" && value.Global == name {
+				// Already bound in this interpreter.
+				continue
+			}
+
+			restored, err := i.restoreValue(value, functions)
+			if err != nil {
+				return fmt.Errorf("restore %s: %w", name, err)
+			}
+			envs[index].define(name, restored)
+		}
+	}
+
+	return nil
+}
+
+func (i *Interpreter) restoreValue(value snapshotValue, functions []*LoxFunction) (interface{}, error) {
+	switch value.Type {
+	case "nil":
+		return nil, nil
+	case "bool":
+		return value.Bool, nil
+	case "number":
+		return strconv.ParseFloat(value.Number, 64)
+	case "string":
+		return value.String, nil
+	case "function":
+		if value.Function < 0 || value.Function >= len(functions) {
+			return nil, fmt.Errorf("bad function %d", value.Function)
+		}
+		return functions[value.Function], nil
+	case "global":
+		global, ok := i.Global(value.Global)
+		if !ok {
+			return nil, fmt.Errorf("global %q is not defined", value.Global)
+		}
+		return global, nil
+	case "module":
+		module, ok := i.hostModules[value.Global]
+		if !ok {
+			return nil, fmt.Errorf("no host module %q", value.Global)
+		}
+		return module, nil
+	}
+
+	return nil, fmt.Errorf("unknown value type %q", value.Type)
+}
+
+// collectFunctions indexes every function declared in stmts, at any depth,
+// by the offset of its name.
+func collectFunctions(stmts []Stmt, found map[int]Function) {
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case Function:
+			found[stmt.name.offset] = stmt
+			collectFunctions(stmt.body, found)
+		case Block:
+			collectFunctions(stmt.statements, found)
+		case If:
+			collectFunctions([]Stmt{*stmt.thenBranch}, found)
+			if stmt.elseBranch != nil {
+				collectFunctions([]Stmt{*stmt.elseBranch}, found)
+			}
+		case While:
+			collectFunctions([]Stmt{stmt.body}, found)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package lox
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	before := NewInterpreter()
+	before.DefineNative("twice", func(x float64) float64 { return 2 * x })
+
+	program, err := Compile(`
+var name = "glox";
+var nothing;
+var nan = 0 / 0;
+var double = twice;
+
+fun makeCounter() {
+    var i = 0;
+    fun count() {
+        i = i + 1;
+        return i;
+    }
+    return count;
+}
+var counter = makeCounter();
+counter();
+
+fun even(n) {
+    if (n == 0) return true;
+    return odd(n - 1);
+}
+fun odd(n) {
+    if (n == 0) return false;
+    return even(n - 1);
+}
+`)
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if err := before.Run(program); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	saved := &bytes.Buffer{}
+	if err := before.Snapshot(saved); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	after := NewInterpreter()
+	after.DefineNative("twice", func(x float64) float64 { return 2 * x })
+	if err := after.Restore(saved); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	cases := []struct {
+		source string
+		want   interface{}
+	}{
+		{"name", "glox"},
+		{"nothing", nil},
+		{"double(21)", 42.0},
+		{"counter()", 2.0},
+		{"counter()", 3.0},
+		{"even(10)", true},
+		{"odd(7)", true},
+	}
+	for _, cc := range cases {
+		got, err := after.Eval(cc.source)
+		if err != nil {
+			t.Fatalf("%s: want no error, got %v", cc.source, err)
+		}
+		if got != cc.want {
+			t.Errorf("%s: want %v, got %v", cc.source, cc.want, got)
+		}
+	}
+
+	if nan, _ := after.Global("nan"); !math.IsNaN(nan.(float64)) {
+		t.Errorf("want NaN, got %v", nan)
+	}
+}
+
+func TestSnapshotREPL(t *testing.T) {
+	before := NewInterpreter()
+	before.SetStdout(&bytes.Buffer{})
+	program, err := CompileREPL("fun f() { return 1; } f")
+	if err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if err := before.Run(program); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	saved := &bytes.Buffer{}
+	if err := before.Snapshot(saved); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	after := NewInterpreter()
+	if err := after.Restore(saved); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	if got, err := after.Eval("f()"); err != nil || got != 1.0 {
+		t.Errorf("want 1, got %v, %v", got, err)
+	}
+}
+
+func TestSnapshotErrors(t *testing.T) {
+	before := NewInterpreter()
+	before.DefineNative("twice", func(x float64) float64 { return 2 * x })
+	if err := before.Run(mustCompile(t, "var double = twice;")); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := &bytes.Buffer{}
+	if err := before.Snapshot(saved); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	// The restoring interpreter doesn't define the native.
+	err := NewInterpreter().Restore(saved)
+	if err == nil || !strings.Contains(err.Error(), `global "twice" is not defined`) {
+		t.Errorf("want undefined native error, got %v", err)
+	}
+
+	// Functions declared without a Program have no source to save.
+	interpreted := NewInterpreter()
+	if err := interpret(interpreted, "fun f() { return 1; }"); err != nil {
+		t.Fatal(err)
+	}
+	err = interpreted.Snapshot(&bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "its source is unknown") {
+		t.Errorf("want unknown source error, got %v", err)
+	}
+
+	if err := NewInterpreter().Restore(strings.NewReader(`{"version": 99}`)); err == nil {
+		t.Error("want version error, got none")
+	}
+}
+
+func mustCompile(t *testing.T, source string) *Program {
+	t.Helper()
+
+	program, err := Compile(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return program
+}
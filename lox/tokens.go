@@ -1,4 +1,4 @@
-package main
+package lox
 
 import "fmt"
 
@@ -7,14 +7,50 @@ type Token struct {
 	lexeme  string
 	literal interface{}
 	line    int
+
+	// offset is where the token starts in the source. It makes otherwise
+	// identical tokens on the same line distinct, which the interpreter relies
+	// on when it looks up resolved variables.
+	offset int
 }
 
 func NewToken(ttype TokenType, lexeme string, literal interface{}, line int) Token {
-	return Token{ttype, lexeme, literal, line}
+	return Token{ttype: ttype, lexeme: lexeme, literal: literal, line: line}
+}
+
+func (t Token) Type() TokenType {
+	return t.ttype
+}
+
+func (t Token) Lexeme() string {
+	return t.lexeme
+}
+
+func (t Token) Literal() interface{} {
+	return t.literal
+}
+
+func (t Token) Line() int {
+	return t.line
 }
 
+// Offset is the byte offset of the token in its source.
+func (t Token) Offset() int {
+	return t.offset
+}
+
+// String shows the token's type and lexeme, and the value of a string or
+// number literal.
 func (t *Token) String() string {
-	return fmt.Sprintf("<token %s %s %s>", t.ttype, t.lexeme, t.literal)
+	switch t.ttype {
+	case EOF:
+		return "EOF"
+	case STRING:
+		return fmt.Sprintf("%s %q %q", t.ttype, t.lexeme, t.literal)
+	case NUMBER:
+		return fmt.Sprintf("%s %q %s", t.ttype, t.lexeme, Stringify(t.literal))
+	}
+	return fmt.Sprintf("%s %q", t.ttype, t.lexeme)
 }
 
 type TokenType string
@@ -57,6 +93,7 @@ const (
 	FUN    = TokenType("fun")
 	FOR    = TokenType("for")
 	IF     = TokenType("if")
+	IMPORT = TokenType("import")
 	NIL    = TokenType("nil")
 	OR     = TokenType("or")
 	PRINT  = TokenType("print")
@@ -67,5 +104,8 @@ const (
 	VAR    = TokenType("var")
 	WHILE  = TokenType("while")
 
+	// Only produced for the formatter, which keeps comments.
+	COMMENT = TokenType("COMMENT")
+
 	EOF = TokenType("")
 )
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime"
+	"time"
+)
+
+// runBench implements "glox bench": it runs a script several times, each with
+// a fresh interpreter, and reports how long the runs took and how much they
+// allocated.
+func runBench(args []string) {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	count := flags.Int("n", 10, "number of times to run the script")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: glox bench [flags] script")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 || *count < 1 {
+		flags.Usage()
+		os.Exit(64)
+	}
+
+	bytes, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(66)
+	}
+	source := string(bytes)
+
+	var durations []time.Duration
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for n := 0; n < *count; n++ {
+		lox := NewLox()
+		// Keep the script's own output out of the report.
+		lox.setOutput(io.Discard, os.Stderr)
+		start := time.Now()
+		lox.run(source)
+		durations = append(durations, time.Since(start))
+
+		if lox.hadError {
+			os.Exit(65)
+		}
+		if lox.hadRuntimeError {
+			os.Exit(70)
+		}
+	}
+	runtime.ReadMemStats(&after)
+
+	mean, stddev := meanStddev(durations)
+	allocs := (after.Mallocs - before.Mallocs) / uint64(*count)
+	allocBytes := (after.TotalAlloc - before.TotalAlloc) / uint64(*count)
+
+	fmt.Printf("runs:    %d\n", *count)
+	fmt.Printf("mean:    %v\n", mean)
+	fmt.Printf("stddev:  %v\n", stddev)
+	fmt.Printf("allocs:  %d allocs/run, %d B/run\n", allocs, allocBytes)
+}
+
+// meanStddev returns the mean of durations and their population standard
+// deviation.
+func meanStddev(durations []time.Duration) (time.Duration, time.Duration) {
+	var sum float64
+	for _, d := range durations {
+		sum += float64(d)
+	}
+	mean := sum / float64(len(durations))
+
+	var squares float64
+	for _, d := range durations {
+		squares += (float64(d) - mean) * (float64(d) - mean)
+	}
+	stddev := math.Sqrt(squares / float64(len(durations)))
+
+	return time.Duration(mean), time.Duration(stddev)
+}
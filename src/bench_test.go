@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMeanStddev(t *testing.T) {
+	cases := []struct {
+		name         string
+		durations    []time.Duration
+		mean, stddev time.Duration
+	}{
+		{"one", []time.Duration{3 * time.Second}, 3 * time.Second, 0},
+		{"same", []time.Duration{time.Millisecond, time.Millisecond}, time.Millisecond, 0},
+		{"spread", []time.Duration{2, 4, 4, 4, 5, 5, 7, 9}, 5, 2},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.name, func(t *testing.T) {
+			mean, stddev := meanStddev(cc.durations)
+			if mean != cc.mean || stddev != cc.stddev {
+				t.Errorf("want %v ± %v, got %v ± %v", cc.mean, cc.stddev, mean, stddev)
+			}
+		})
+	}
+}
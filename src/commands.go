@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/n4to4/glox/lox"
+)
+
+// commandHelp lists the commands the prompt accepts.
+const commandHelp = `:load file   run a file in this session
+:reset       start over with a fresh interpreter
+:env         list the globals and their values
+:tokens src  show the tokens scanned from src
+:ast src     show the syntax tree parsed from src
+:time expr   evaluate expr and show how long it took
+:help        show this list`
+
+// command runs a line typed at the prompt that starts with ':'.
+func (l *Lox) command(line string) {
+	name, arg := line, ""
+	if space := strings.IndexAny(line, " \t"); space >= 0 {
+		name, arg = line[:space], strings.TrimSpace(line[space:])
+	}
+
+	switch name {
+	case ":load":
+		l.load(arg)
+	case ":reset":
+		l.reset()
+	case ":env":
+		for _, name := range l.interpreter.Globals() {
+			value, _ := l.interpreter.Global(name)
+			fmt.Fprintf(l.stdout, "%s = %s\n", name, lox.Stringify(value))
+		}
+	case ":tokens":
+		l.printTokens(arg)
+	case ":ast":
+		l.printAst(arg)
+	case ":time":
+		l.time(arg)
+	case ":help":
+		fmt.Fprintln(l.stdout, commandHelp)
+	default:
+		fmt.Fprintf(l.stderr, "unknown command %s; try :help\n", name)
+	}
+}
+
+func (l *Lox) load(file string) {
+	if file == "" {
+		fmt.Fprintln(l.stderr, "usage: :load file")
+		return
+	}
+	bytes, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintln(l.stderr, err)
+		return
+	}
+
+	// Run it as a script, so its imports are relative to it and its
+	// trailing expressions aren't echoed.
+	l.interpreter.SetModuleLoader(lox.OSLoader{Dir: filepath.Dir(file)})
+	l.interactive = false
+	defer func() {
+		l.interpreter.SetModuleLoader(lox.OSLoader{})
+		l.interactive = true
+	}()
+	l.run(string(bytes))
+}
+
+func (l *Lox) printTokens(source string) {
+	if err := dumpTokens(l.stdout, source); err != nil {
+		l.error(err)
+	}
+}
+
+// printAst shows the statements in source, or source as an expression if it
+// doesn't parse as statements.
+func (l *Lox) printAst(source string) {
+	tokens, err := lox.Scan(source)
+	if err != nil {
+		l.error(err)
+		return
+	}
+
+	printer := lox.AstPrinter{}
+	stmts, err := lox.Parse(tokens)
+	if err != nil {
+		expr, exprErr := lox.ParseExpression(tokens)
+		if exprErr != nil {
+			l.error(err)
+			return
+		}
+		ast, _ := printer.Print(expr)
+		fmt.Fprintln(l.stdout, ast)
+	}
+
+	for _, stmt := range stmts {
+		ast, _ := printer.PrintStmt(stmt)
+		fmt.Fprintln(l.stdout, ast)
+	}
+}
+
+func (l *Lox) time(expr string) {
+	ctx, cancel := l.context()
+	defer cancel()
+
+	start := time.Now()
+	value, err := l.interpreter.EvalContext(ctx, expr)
+	elapsed := time.Since(start)
+	if err != nil {
+		l.runtimeError(err)
+		return
+	}
+	fmt.Fprintf(l.stdout, "%s\n(%v)\n", lox.Stringify(value), elapsed)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// repl types input at the prompt and returns what was written to stdout,
+// without the prompts, and to stderr.
+func repl(t *testing.T, input string) (string, string) {
+	t.Helper()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	l := NewLox()
+	l.setOutput(stdout, stderr)
+	l.stdin = strings.NewReader(input)
+	l.runPrompt()
+
+	output := strings.ReplaceAll(stdout.String(), "... ", "")
+	return strings.ReplaceAll(output, "> ", ""), stderr.String()
+}
+
+func TestCommand(t *testing.T) {
+	dir := writeScripts(t, map[string]string{
+		"lib.lox": "var loaded = \"yes\";\nloaded;\n",
+	})
+	lib := filepath.Join(dir, "lib.lox")
+
+	cases := []struct {
+		name   string
+		input  string
+		stdout string
+		stderr string
+	}{
+		{"echo", "1 + 2\n", "3\n", ""},
+		{"help", ":help\n", commandHelp + "\n", ""},
+		{"unknown", ":nope\n", "", "unknown command :nope; try :help\n"},
+		{"env", "var a = 1;\nfun f() {}\n:env\n", "a = 1\nargs = []\nclock = <native fn>\nexit = <native fn>\nf = <fn f>\ngetenv = <native fn>\nreadLine = <native fn>\n", ""},
+		{"reset", "var a = 1;\n:reset\na\n", "", "undefined variable \"a\"\n[line 1]\n"},
+		{"tokens", ":tokens print 1;\n", "1:1\tprint \"print\"\n1:7\tNUMBER \"1\" 1\n1:8\t; \";\"\n1:9\tEOF\n", ""},
+		{"ast statements", ":ast print \"a\"; var b;\n", "(print \"a\")\n(var b)\n", ""},
+		{"ast expression", ":ast 1 + -x\n", "(+ 1 (- x))\n", ""},
+		{"ast error", ":ast print\n", "", "[line 1] Error at end: Expect expression.\n"},
+		{"load", ":load " + lib + "\nloaded\n", "yes\n", ""},
+		{"load nothing", ":load\n", "", "usage: :load file\n"},
+		{"load missing", ":load " + filepath.Join(dir, "missing.lox") + "\n", "", "no such file"},
+		{"time error", ":time nope\n", "", "undefined variable"},
+		{"command mid-statement", "fun f() {\n:env\n}\n", "", "Unexpected character."},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.name, func(t *testing.T) {
+			stdout, stderr := repl(t, cc.input)
+			if stdout != cc.stdout {
+				t.Errorf("want output %q, got %q", cc.stdout, stdout)
+			}
+			if !strings.Contains(stderr, cc.stderr) || cc.stderr == "" && stderr != "" {
+				t.Errorf("want errors %q, got %q", cc.stderr, stderr)
+			}
+		})
+	}
+}
+
+func TestTimeCommand(t *testing.T) {
+	stdout, stderr := repl(t, ":time 1 + 2\n")
+	if stderr != "" {
+		t.Fatalf("want no errors, got %q", stderr)
+	}
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if len(lines) != 2 || lines[0] != "3" || !strings.HasPrefix(lines[1], "(") || !strings.HasSuffix(lines[1], ")") {
+		t.Errorf("want the value and the time taken, got %q", stdout)
+	}
+}
+
+func TestLoadCommandImports(t *testing.T) {
+	dir := writeScripts(t, map[string]string{
+		"main.lox": "import \"lib.lox\";\n",
+		"lib.lox":  "var answer = 42;\n",
+	})
+
+	// Imports are relative to the loaded file, not the current directory.
+	stdout, stderr := repl(t, ":load "+filepath.Join(dir, "main.lox")+"\nanswer\n")
+	if stdout != "42\n" || stderr != "" {
+		t.Errorf("want 42, got %q and errors %q", stdout, stderr)
+	}
+}
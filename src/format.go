@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/n4to4/glox/lox"
+)
+
+// runFmt implements "glox fmt": it prints scripts in the standard layout, or
+// rewrites them in place with -w. With no scripts it formats stdin.
+func runFmt(args []string) {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	write := flags.Bool("w", false, "write the result to the script instead of stdout")
+	list := flags.Bool("l", false, "list scripts whose formatting differs instead of printing them")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: glox fmt [flags] [script...]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		source, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(66)
+		}
+		formatted, err := lox.Format(string(source))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(65)
+		}
+		fmt.Print(formatted)
+		return
+	}
+
+	failed := false
+	for _, file := range flags.Args() {
+		source := readScript(file)
+		formatted, err := lox.Format(source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s:\n%v\n", file, err)
+			failed = true
+			continue
+		}
+
+		switch {
+		case *list:
+			if formatted != source {
+				fmt.Println(file)
+			}
+		case *write:
+			if formatted != source {
+				if err := os.WriteFile(file, []byte(formatted), 0666); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					failed = true
+				}
+			}
+		default:
+			fmt.Print(formatted)
+		}
+	}
+	if failed {
+		os.Exit(65)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/n4to4/glox/lox"
+)
+
+// runCheck implements "glox check": it reports the scan, parse and resolve
+// errors in scripts without running them.
+func runCheck(args []string) {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: glox check script...")
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(64)
+	}
+
+	failed := false
+	for _, file := range flags.Args() {
+		if _, err := lox.Compile(readScript(file)); err != nil {
+			fmt.Fprintf(os.Stderr, "%s:\n%v\n", file, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(65)
+	}
+}
+
+// runTokens implements "glox tokens": it prints the tokens in a script, one
+// per line.
+func runTokens(args []string) {
+	source := readScript(frontendArg("tokens", args))
+	if err := dumpTokens(os.Stdout, source); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(65)
+	}
+}
+
+// runAst implements "glox ast": it prints a script's syntax tree as indented
+// S-expressions, or as JSON with -json.
+func runAst(args []string) {
+	flags := flag.NewFlagSet("ast", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print the tree as JSON")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: glox ast [flags] script")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(64)
+	}
+
+	format := astSExpr
+	if *asJSON {
+		format = astJSON
+	}
+	if err := dumpAst(os.Stdout, readScript(flags.Arg(0)), format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(65)
+	}
+}
+
+// dumpTokens writes the tokens in source one per line, with the line and
+// column each starts at. Tokens scanned before any errors are written too.
+func dumpTokens(w io.Writer, source string) error {
+	tokens, err := lox.Scan(source)
+	for _, token := range tokens {
+		// Token.Line is where a token ends, which differs for strings
+		// spanning lines.
+		before := source[:token.Offset()]
+		line := strings.Count(before, "\n") + 1
+		column := len(before) - strings.LastIndex(before, "\n")
+		fmt.Fprintf(w, "%d:%d\t%v\n", line, column, &token)
+	}
+	return err
+}
+
+// Formats for dumpAst.
+const (
+	astSExpr = "sexpr"
+	astJSON  = "json"
+)
+
+// dumpAst writes the statements in source as indented S-expressions or as
+// JSON.
+func dumpAst(w io.Writer, source, format string) error {
+	tokens, err := lox.Scan(source)
+	if err != nil {
+		return err
+	}
+	stmts, err := lox.Parse(tokens)
+	if err != nil {
+		return err
+	}
+
+	if format == astJSON {
+		data, err := lox.MarshalAST(stmts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\n", data)
+		return nil
+	}
+
+	printer := lox.AstPrinter{Indent: "    "}
+	for _, stmt := range stmts {
+		ast, err := printer.PrintStmt(stmt)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, ast)
+	}
+	return nil
+}
+
+// dump prints the tokens, the syntax tree in the given format, or both, for
+// run's --dump-tokens and --dump-ast, exiting if source has errors.
+func dump(source string, tokens bool, astFormat string) {
+	if tokens {
+		if err := dumpTokens(os.Stdout, source); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(65)
+		}
+	}
+	if astFormat != "" {
+		if err := dumpAst(os.Stdout, source, astFormat); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(65)
+		}
+	}
+}
+
+// frontendArg parses the arguments of a command that takes one script.
+func frontendArg(name string, args []string) string {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: glox %s script\n", name)
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(64)
+	}
+	return flags.Arg(0)
+}
+
+// readScript reads a script, or stdin if file is "-", exiting if it can't.
+func readScript(file string) string {
+	var bytes []byte
+	var err error
+	if file == "-" {
+		bytes, err = io.ReadAll(os.Stdin)
+	} else {
+		bytes, err = os.ReadFile(file)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(66)
+	}
+	return string(bytes)
+}
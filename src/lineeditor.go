@@ -0,0 +1,456 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// maxHistory is how many lines of history are kept.
+const maxHistory = 1000
+
+// errInterrupted is returned by readLine when the user presses Ctrl-C.
+var errInterrupted = errors.New("interrupted")
+
+// lineReader reads the lines typed at the prompt.
+type lineReader interface {
+	// readLine shows prompt and returns the next line, ending in '\n'.
+	readLine(prompt string) (string, error)
+}
+
+// plainReader reads lines as they come, for when stdin isn't a terminal.
+type plainReader struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func (r plainReader) readLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	return r.in.ReadString('\n')
+}
+
+// lineEditor reads lines from a terminal in raw mode, with emacs-style
+// editing keys, history and tab completion. The terminal is only raw while a
+// line is being read, so scripts see it as usual.
+type lineEditor struct {
+	fd          uintptr
+	in          *bufio.Reader
+	out         io.Writer
+	history     []string
+	historyFile string          // "" to keep history in memory only
+	fileLines   int             // lines in historyFile
+	complete    func() []string // words that Tab completes
+
+	// The line being edited.
+	prompt       string
+	buf          []rune
+	pos          int
+	historyIndex int
+	saved        []rune // the new line, while browsing history
+}
+
+func newLineEditor(fd uintptr, in *bufio.Reader, out io.Writer, historyFile string, complete func() []string) *lineEditor {
+	e := &lineEditor{
+		fd:          fd,
+		in:          in,
+		out:         out,
+		historyFile: historyFile,
+		complete:    complete,
+	}
+	e.loadHistory()
+	return e
+}
+
+// historyPath is where the REPL's history is kept, or "" if there is no home
+// directory.
+func historyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return home + string(os.PathSeparator) + ".glox_history"
+}
+
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	restore, err := makeRaw(e.fd)
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	return e.edit(prompt)
+}
+
+// edit reads keys until a line is finished. The terminal must be raw.
+func (e *lineEditor) edit(prompt string) (string, error) {
+	e.prompt, e.buf, e.pos = prompt, nil, 0
+	e.historyIndex, e.saved = len(e.history), nil
+	e.refresh()
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			return e.accept(), nil
+		case ctrl('C'):
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errInterrupted
+		case ctrl('D'):
+			if len(e.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.delete()
+		case ctrl('A'):
+			e.pos = 0
+		case ctrl('E'):
+			e.pos = len(e.buf)
+		case ctrl('B'):
+			e.moveBy(-1)
+		case ctrl('F'):
+			e.moveBy(1)
+		case ctrl('H'), 127:
+			if e.pos > 0 {
+				e.pos--
+				e.delete()
+			}
+		case ctrl('K'):
+			e.buf = e.buf[:e.pos]
+		case ctrl('U'):
+			e.buf = append([]rune(nil), e.buf[e.pos:]...)
+			e.pos = 0
+		case ctrl('W'):
+			e.deleteWord()
+		case ctrl('L'):
+			fmt.Fprint(e.out, "\x1b[H\x1b[2J")
+		case ctrl('P'):
+			e.browse(-1)
+		case ctrl('N'):
+			e.browse(1)
+		case ctrl('R'):
+			run, err := e.search()
+			if err != nil {
+				return "", err
+			}
+			if run {
+				return e.accept(), nil
+			}
+		case '\t':
+			e.completeWord()
+		case 27:
+			if err := e.escape(); err != nil {
+				return "", err
+			}
+		default:
+			if r >= ' ' {
+				e.insert(r)
+			}
+		}
+		e.refresh()
+	}
+}
+
+func ctrl(key rune) rune {
+	return key & 0x1f
+}
+
+// accept finishes the line and records it in the history.
+func (e *lineEditor) accept() string {
+	fmt.Fprint(e.out, "\r\n")
+	line := string(e.buf)
+	e.addHistory(line)
+	return line + "\n"
+}
+
+// refresh redraws the prompt and line and puts the cursor in place.
+func (e *lineEditor) refresh() {
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", e.prompt, string(e.buf))
+	if back := len(e.buf) - e.pos; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
+
+func (e *lineEditor) insert(r rune) {
+	e.buf = append(e.buf, 0)
+	copy(e.buf[e.pos+1:], e.buf[e.pos:])
+	e.buf[e.pos] = r
+	e.pos++
+}
+
+// delete removes the character under the cursor.
+func (e *lineEditor) delete() {
+	if e.pos < len(e.buf) {
+		e.buf = append(e.buf[:e.pos], e.buf[e.pos+1:]...)
+	}
+}
+
+// deleteWord removes the whitespace-separated word before the cursor, along
+// with any spaces between it and the cursor.
+func (e *lineEditor) deleteWord() {
+	start := e.pos
+	for start > 0 && e.buf[start-1] == ' ' {
+		start--
+	}
+	for start > 0 && e.buf[start-1] != ' ' {
+		start--
+	}
+	e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+	e.pos = start
+}
+
+func (e *lineEditor) moveBy(n int) {
+	if pos := e.pos + n; pos >= 0 && pos <= len(e.buf) {
+		e.pos = pos
+	}
+}
+
+// escape handles the escape sequences sent by arrow and editing keys. A
+// terminal sends a sequence all at once, so an Esc with nothing after it was
+// typed on its own and is ignored.
+func (e *lineEditor) escape() error {
+	if e.in.Buffered() == 0 {
+		return nil
+	}
+	r, _, err := e.in.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return err
+	}
+
+	var seq []rune
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return err
+		}
+		seq = append(seq, r)
+		if r < '0' || r > '9' {
+			break
+		}
+	}
+
+	switch string(seq) {
+	case "A":
+		e.browse(-1)
+	case "B":
+		e.browse(1)
+	case "C":
+		e.moveBy(1)
+	case "D":
+		e.moveBy(-1)
+	case "H", "1~", "7~":
+		e.pos = 0
+	case "F", "4~", "8~":
+		e.pos = len(e.buf)
+	case "3~":
+		e.delete()
+	}
+	return nil
+}
+
+// browse moves through the history by delta lines, keeping the new line
+// being typed at the end.
+func (e *lineEditor) browse(delta int) {
+	index := e.historyIndex + delta
+	if index < 0 || index > len(e.history) {
+		return
+	}
+
+	if e.historyIndex == len(e.history) {
+		e.saved = e.buf
+	}
+	e.historyIndex = index
+	if index == len(e.history) {
+		e.buf = e.saved
+	} else {
+		e.buf = []rune(e.history[index])
+	}
+	e.pos = len(e.buf)
+}
+
+// search runs an incremental reverse search of the history. It reports
+// whether the user pressed Enter to run the line found; any other key that
+// isn't part of the search leaves the line found to be edited.
+func (e *lineEditor) search() (bool, error) {
+	var query []rune
+	index, found := len(e.history), true
+	original, originalPos := e.buf, e.pos
+
+	// find looks for the query in history lines before end.
+	find := func(end int) {
+		for k := end - 1; k >= 0; k-- {
+			if strings.Contains(e.history[k], string(query)) {
+				index, found = k, true
+				return
+			}
+		}
+		found = false
+	}
+	match := func() string {
+		if index == len(e.history) {
+			return ""
+		}
+		return e.history[index]
+	}
+
+	for {
+		status := "reverse-i-search"
+		if !found {
+			status = "failed " + status
+		}
+		fmt.Fprintf(e.out, "\r(%s)'%s': %s\x1b[K", status, string(query), match())
+
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return false, err
+		}
+
+		switch {
+		case r == ctrl('R'):
+			find(index)
+		case r == ctrl('H') || r == 127:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				index, found = len(e.history), true
+				if len(query) > 0 {
+					find(index)
+				}
+			}
+		case r == ctrl('C') || r == ctrl('G'):
+			e.buf, e.pos = original, originalPos
+			return false, nil
+		case r == '\r' || r == '\n':
+			if line := match(); line != "" {
+				e.buf = []rune(line)
+				e.pos = len(e.buf)
+			}
+			return true, nil
+		case r >= ' ' && r != 127:
+			query = append(query, r)
+			// The current match may still do.
+			end := index
+			if end < len(e.history) {
+				end++
+			}
+			find(end)
+		default:
+			if line := match(); line != "" {
+				e.buf = []rune(line)
+				e.pos = len(e.buf)
+			}
+			return false, nil
+		}
+	}
+}
+
+// completeWord completes the word before the cursor as far as the words it
+// could be agree, and lists them if that adds nothing.
+func (e *lineEditor) completeWord() {
+	start := e.wordStart()
+	prefix := string(e.buf[start:e.pos])
+	if prefix == "" {
+		return
+	}
+
+	var matches []string
+	for _, word := range e.complete() {
+		if strings.HasPrefix(word, prefix) {
+			matches = append(matches, word)
+		}
+	}
+	if len(matches) == 0 {
+		return
+	}
+
+	common := matches[0]
+	for _, match := range matches[1:] {
+		for !strings.HasPrefix(match, common) {
+			common = common[:len(common)-1]
+		}
+	}
+
+	if common != prefix {
+		for _, r := range common[len(prefix):] {
+			e.insert(r)
+		}
+		return
+	}
+	if len(matches) > 1 {
+		fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(matches, "  "))
+	}
+}
+
+// wordStart finds the start of the identifier that ends at the cursor.
+func (e *lineEditor) wordStart() int {
+	start := e.pos
+	for start > 0 && isWordRune(e.buf[start-1]) {
+		start--
+	}
+	return start
+}
+
+func isWordRune(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_'
+}
+
+// loadHistory reads the last maxHistory lines of the history file. History
+// is a convenience, so a missing or unreadable file is ignored.
+func (e *lineEditor) loadHistory() {
+	if e.historyFile == "" {
+		return
+	}
+	bytes, err := os.ReadFile(e.historyFile)
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(bytes), "\n") {
+		if line != "" {
+			e.history = append(e.history, line)
+		}
+	}
+	e.fileLines = len(e.history)
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+	}
+}
+
+// addHistory records line, appending it to the history file too. Once the
+// file holds maxHistory lines it is rewritten with just the lines kept.
+func (e *lineEditor) addHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if len(e.history) > 0 && e.history[len(e.history)-1] == line {
+		return
+	}
+
+	e.history = append(e.history, line)
+	if len(e.history) > maxHistory {
+		e.history = e.history[1:]
+	}
+
+	if e.historyFile == "" {
+		return
+	}
+	if e.fileLines >= maxHistory {
+		if os.WriteFile(e.historyFile, []byte(strings.Join(e.history, "\n")+"\n"), 0600) == nil {
+			e.fileLines = len(e.history)
+		}
+		return
+	}
+	file, err := os.OpenFile(e.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	if _, err := fmt.Fprintln(file, line); err == nil {
+		e.fileLines++
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// chunkReader returns one chunk per Read, the way a terminal hands over
+// each key or escape sequence as it is typed.
+type chunkReader struct {
+	chunks []string
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.chunks[0])
+	r.chunks[0] = r.chunks[0][n:]
+	if r.chunks[0] == "" {
+		r.chunks = r.chunks[1:]
+	}
+	return n, nil
+}
+
+func newTestEditor(history []string, keys ...string) *lineEditor {
+	return &lineEditor{
+		in:       bufio.NewReader(&chunkReader{keys}),
+		out:      io.Discard,
+		history:  append([]string(nil), history...),
+		complete: func() []string { return []string{"print", "println", "var"} },
+	}
+}
+
+func TestLineEditor(t *testing.T) {
+	history := []string{"one", "two", "bone"}
+
+	cases := []struct {
+		name string
+		keys []string
+		want string
+		err  error
+	}{
+		{"typing", []string{"abc\r"}, "abc\n", nil},
+		{"insert", []string{"ac\x02b\r"}, "abc\n", nil},
+		{"delete", []string{"abc\x01\x04\r"}, "bc\n", nil},
+		{"backspace", []string{"abc\x7f\r"}, "ab\n", nil},
+		{"backspace at start", []string{"abc\x01\x7f\r"}, "abc\n", nil},
+		{"kill to end", []string{"abc\x02\x02\x0b\r"}, "a\n", nil},
+		{"kill to start", []string{"abc\x02\x15\r"}, "c\n", nil},
+		{"delete word", []string{"var x = foo\x17\r"}, "var x = \n", nil},
+		{"delete word after space", []string{"print foo \x17\r"}, "print \n", nil},
+		{"delete punctuated word", []string{"print f(x)\x17\r"}, "print \n", nil},
+		{"arrow keys", []string{"ac", "\x1b[D", "b", "\x1b[C", "d\r"}, "abcd\n", nil},
+		{"home and delete keys", []string{"abc", "\x1b[H", "\x1b[3~", "\r"}, "bc\n", nil},
+		{"bare escape", []string{"ab", "\x1b", "c\r"}, "abc\n", nil},
+		{"previous", []string{"\x1b[A", "\r"}, "bone\n", nil},
+		{"previous twice", []string{"\x10\x10\r"}, "two\n", nil},
+		{"back to new line", []string{"new\x10\x10\x0e\x0e\r"}, "new\n", nil},
+		{"past oldest", []string{"\x10\x10\x10\x10\r"}, "one\n", nil},
+		{"search", []string{"\x12on\r"}, "bone\n", nil},
+		{"search again", []string{"\x12on\x12\r"}, "one\n", nil},
+		{"search and edit", []string{"\x12tw\x06!\r"}, "two!\n", nil},
+		{"search cancelled", []string{"ab\x12tw\x07\r"}, "ab\n", nil},
+		{"failed search", []string{"\x12xyz\r"}, "\n", nil},
+		{"search backspace", []string{"\x12onx\x7f\r"}, "bone\n", nil},
+		{"complete", []string{"pr\t\r"}, "print\n", nil},
+		{"complete unique", []string{"v\t\r"}, "var\n", nil},
+		{"complete ambiguous", []string{"print\t\r"}, "print\n", nil},
+		{"complete nothing", []string{"x\t\r"}, "x\n", nil},
+		{"interrupt", []string{"abc\x03"}, "", errInterrupted},
+		{"end of input", []string{"\x04"}, "", io.EOF},
+		{"ctrl-D deletes", []string{"ab\x02\x04\r"}, "a\n", nil},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.name, func(t *testing.T) {
+			e := newTestEditor(history, cc.keys...)
+			got, err := e.edit("> ")
+			if err != cc.err {
+				t.Fatalf("want error %v, got %v", cc.err, err)
+			}
+			if got != cc.want {
+				t.Errorf("want %q, got %q", cc.want, got)
+			}
+		})
+	}
+}
+
+func TestLineEditorHistory(t *testing.T) {
+	e := newTestEditor(nil, "a\r", "a\r", " \r", "b\r")
+	for {
+		if _, err := e.edit("> "); err != nil {
+			break
+		}
+	}
+
+	if want := []string{"a", "b"}; !reflect.DeepEqual(e.history, want) {
+		t.Errorf("want history %q, got %q", want, e.history)
+	}
+}
+
+func TestLoadHistory(t *testing.T) {
+	var many []string
+	for k := 0; k < maxHistory+5; k++ {
+		many = append(many, fmt.Sprint("line ", k))
+	}
+
+	cases := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{"lines", "one\ntwo\n", []string{"one", "two"}},
+		{"blank lines", "one\n\n\ntwo", []string{"one", "two"}},
+		{"empty", "", nil},
+		{"too many", strings.Join(many, "\n"), many[5:]},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "history")
+			if err := os.WriteFile(file, []byte(cc.file), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			e := &lineEditor{historyFile: file}
+			e.loadHistory()
+			if !reflect.DeepEqual(e.history, cc.want) {
+				t.Errorf("want %d lines ending %q, got %d", len(cc.want), last(cc.want), len(e.history))
+			}
+		})
+	}
+
+	e := &lineEditor{historyFile: filepath.Join(t.TempDir(), "missing")}
+	e.loadHistory()
+	if len(e.history) != 0 {
+		t.Errorf("want no history from a missing file, got %q", e.history)
+	}
+}
+
+func TestAddHistory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history")
+
+	e := &lineEditor{historyFile: file}
+	e.loadHistory()
+	for k := 0; k < maxHistory+5; k++ {
+		e.addHistory(fmt.Sprint("line ", k))
+	}
+
+	bytes, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(bytes), "\n"), "\n")
+	if len(lines) != maxHistory {
+		t.Errorf("want the file capped at %d lines, got %d", maxHistory, len(lines))
+	}
+	if want := fmt.Sprint("line ", maxHistory+4); last(lines) != want {
+		t.Errorf("want last line %q, got %q", want, last(lines))
+	}
+	if !reflect.DeepEqual(lines, e.history) {
+		t.Error("want the file to hold the history kept")
+	}
+}
+
+func last(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return lines[len(lines)-1]
+}
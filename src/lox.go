@@ -2,72 +2,230 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"os"
-)
+	"path/filepath"
+	"strings"
+	"time"
 
-const (
-	ErrOperandMustBeANumber     = "operand must be a number"
-	ErrOperandsMustBeNumbers    = "operands must be numbers"
-	ErrOperandsMustBeNumsOrStrs = "operands must be two numbers or two strings"
+	"github.com/n4to4/glox/lox"
 )
 
-type RuntimeError struct {
-	token   Token
-	message string
-}
-
-func (e RuntimeError) Error() string {
-	return fmt.Sprintf("%s\n[line %d]", e.message, e.token.line)
-}
-
 type Lox struct {
-	interpreter     *Interpreter
+	interpreter     *lox.Interpreter
+	timeout         time.Duration
+	maxSteps        int
+	maxMemory       int
+	args            []string // arguments for the script
+	stdout          io.Writer
+	stderr          io.Writer
+	stdin           io.Reader
+	input           *bufio.Reader   // stdin as the prompt reads it
+	interactive     bool            // echo trailing expressions, as at the prompt
+	watching        bool            // keep going after exit(), as --watch does
+	ctx             context.Context // cancels runs, if not nil
 	hadError        bool
 	hadRuntimeError bool
 }
 
 func NewLox() Lox {
-	return Lox{
-		interpreter:     NewInterpreter(),
+	l := Lox{
+		stdout:          os.Stdout,
+		stderr:          os.Stderr,
+		stdin:           os.Stdin,
 		hadError:        false,
 		hadRuntimeError: false,
 	}
+	l.reset()
+	return l
+}
+
+// reset starts over with a fresh interpreter, configured as the last one
+// was.
+func (l *Lox) reset() {
+	l.interpreter = newInterpreter(l.args)
+	l.interpreter.SetStdout(l.stdout)
+	l.interpreter.SetMaxSteps(l.maxSteps)
+	l.interpreter.SetMaxMemory(l.maxMemory)
+	if l.input != nil {
+		l.interpreter.SetStdin(l.input)
+	}
+}
+
+// newInterpreter makes an interpreter with the globals every script run by
+// glox can use: args, holding the script's arguments, and getenv.
+func newInterpreter(args []string) *lox.Interpreter {
+	interpreter := lox.NewInterpreter()
+
+	values := make([]interface{}, len(args))
+	for k, arg := range args {
+		values[k] = arg
+	}
+	interpreter.Define("args", interpreter.NewList(values...))
+	interpreter.DefineNative("getenv", getenv)
+	return interpreter
 }
 
+// getenv returns the value of an environment variable, or nil if it isn't
+// set.
+func getenv(name string) interface{} {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	return value
+}
+
+// setOutput sends script output and diagnostics to stdout and stderr.
+func (l *Lox) setOutput(stdout, stderr io.Writer) {
+	l.stdout = stdout
+	l.stderr = stderr
+	l.interpreter.SetStdout(stdout)
+}
+
+// runFile runs a script, or the program on stdin if file is "-".
 func (l *Lox) runFile(file string) {
-	bytes, err := os.ReadFile(file)
+	var bytes []byte
+	var err error
+	if file == "-" {
+		bytes, err = io.ReadAll(l.stdin)
+	} else {
+		bytes, err = os.ReadFile(file)
+	}
 	if err != nil {
-		log.Fatal(err)
+		fmt.Fprintln(l.stderr, err)
+		os.Exit(66)
 	}
 
-	l.run(string(bytes))
+	// Imports from a program on stdin are relative to the current directory.
+	dir := "."
+	if file != "-" {
+		dir = filepath.Dir(file)
+	}
+	l.runScript(string(bytes), dir)
+}
+
+// runScript runs source as a whole program, with imports relative to dir,
+// and exits with an error status if it fails.
+func (l *Lox) runScript(source, dir string) {
+	l.interpreter.SetModuleLoader(lox.OSLoader{Dir: dir})
+	l.run(source)
+
+	if l.hadError {
+		os.Exit(65)
+	}
+	if l.hadRuntimeError {
+		os.Exit(70)
+	}
 }
 
 func (l *Lox) runPrompt() {
-	scanner := bufio.NewScanner(os.Stdin)
-	prompt := func() { fmt.Print("> ") }
-	for prompt(); scanner.Scan(); prompt() {
-		line := scanner.Text()
-		l.run(line)
+	// The prompt and scripts share stdin, so hand the interpreter the same
+	// buffered reader the prompt uses.
+	l.input = bufio.NewReader(l.stdin)
+	l.interpreter.SetStdin(l.input)
+	l.interactive = true
+
+	var reader lineReader = plainReader{l.input, l.stdout}
+	if file, ok := l.stdin.(*os.File); ok && isTerminal(file.Fd()) {
+		reader = newLineEditor(file.Fd(), l.input, l.stdout, historyPath(), l.completions)
+	}
+
+	var source strings.Builder
+	for {
+		// Keep reading until the input so far is complete.
+		prompt := "> "
+		if source.Len() > 0 {
+			prompt = "... "
+		}
+		line, err := reader.readLine(prompt)
+		if err == errInterrupted {
+			source.Reset()
+			continue
+		}
+		if source.Len() == 0 && strings.HasPrefix(line, ":") {
+			l.command(strings.TrimSpace(line))
+			l.hadError = false
+			if err != nil {
+				return
+			}
+			continue
+		}
+		source.WriteString(line)
+		if err == nil && lox.Incomplete(source.String()) {
+			continue
+		}
+		if source.Len() == 0 {
+			return
+		}
+
+		l.run(source.String())
+		l.hadError = false
+		source.Reset()
+		if err != nil {
+			return
+		}
 	}
 }
 
-func (l *Lox) run(source string) {
-	scanner := NewScanner(source)
-	tokens := scanner.ScanTokens()
+// completions are the words the line editor completes: keywords and the
+// names of globals.
+func (l *Lox) completions() []string {
+	return append(lox.Keywords(), l.interpreter.Globals()...)
+}
 
-	parser := Parser{tokens: tokens}
-	stmts := parser.Parse()
+func (l *Lox) run(source string) {
+	compile := lox.Compile
+	if l.interactive {
+		compile = lox.CompileREPL
+	}
+	program, err := compile(source)
 
 	// stop if there was a syntax error
-	if l.hadError {
+	if err != nil {
+		l.error(err)
+		return
+	}
+
+	ctx, cancel := l.context()
+	defer cancel()
+
+	err = l.interpreter.RunContext(ctx, program)
+	var exit lox.ExitError
+	if errors.As(err, &exit) && !l.watching {
+		// The script has finished unwinding, so it's safe to stop here.
+		os.Exit(exit.Code)
+	}
+	if l.watching && (errors.As(err, &exit) && exit.Code == 0 || errors.Is(err, context.Canceled)) {
+		// The script ended normally, or a change stopped it to run it again.
 		return
 	}
+	if err != nil {
+		l.runtimeError(err)
+	}
+}
+
+// context limits a run to the timeout, if there is one.
+func (l *Lox) context() (context.Context, context.CancelFunc) {
+	parent := context.Background()
+	if l.ctx != nil {
+		parent = l.ctx
+	}
+	if l.timeout > 0 {
+		return context.WithTimeout(parent, l.timeout)
+	}
+	return context.WithCancel(parent)
+}
 
-	resolver := NewResolver(l.interpreter)
-	resolver.resolveStmts(stmts)
+func (l *Lox) error(err error) {
+	fmt.Fprintln(l.stderr, err)
+	l.hadError = true
+}
 
-	l.interpreter.Interpret(stmts)
+func (l *Lox) runtimeError(err error) {
+	fmt.Fprintln(l.stderr, err)
+	l.hadRuntimeError = true
 }
@@ -1,20 +1,150 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 )
 
+// subcommands maps the first argument to the command it names. Anything else
+// is taken as the script for "glox run".
+var subcommands = map[string]func(args []string){
+	"run":    runRun,
+	"check":  runCheck,
+	"tokens": runTokens,
+	"ast":    runAst,
+	"fmt":    runFmt,
+	"test":   runTest,
+	"bench":  runBench,
+}
+
+const usage = `Usage: glox [flags] [script [arguments...]]
+       glox [flags] -e source [-e source...] [arguments...]
+       glox <command> [flags] [arguments...]
+
+Commands:
+  run     run a script, or start a prompt without one; "-" reads the
+          script from stdin
+  check   scan, parse and resolve scripts without running them
+  tokens  print the tokens scanned from a script
+  ast     print the syntax tree parsed from a script
+  fmt     format scripts
+  test    run scripts and check their output
+  bench   time repeated runs of a script
+
+"glox script" is short for "glox run script".`
+
 func main() {
-	if len(os.Args) > 2 {
-		fmt.Fprintln(os.Stderr, "Usage: glox [script]")
-		os.Exit(64)
+	args := os.Args[1:]
+	if len(args) > 0 {
+		if command, ok := subcommands[args[0]]; ok {
+			command(args[1:])
+			return
+		}
 	}
+	runRun(args)
+}
+
+// runRun implements "glox run": it runs a script, passing it the arguments
+// that follow, or starts a prompt if there is no script.
+func runRun(args []string) {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	timeout := flags.Duration("timeout", 0, "stop the script after this much wall-clock time")
+	maxSteps := flags.Int("max-steps", 0, "stop the script after executing this many statements")
+	maxMemory := flags.Int("max-memory", 0, "stop the script after it has allocated roughly this many bytes in total")
+	watch := flags.Bool("watch", false, "run the script again whenever it or a module it imports changes")
+	dumpTokensFlag := flags.Bool("dump-tokens", false, "print the script's tokens instead of running it")
+	var dumpAstFlag astFormatFlag
+	flags.Var(&dumpAstFlag, "dump-ast", "print the script's syntax tree instead of running it; =json for JSON")
+	var sources sourceList
+	flags.Var(&sources, "e", "run this `source` instead of a script; repeat to add lines")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, usage)
+		fmt.Fprintln(os.Stderr, "\nFlags for run:")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
 
 	lox := NewLox()
-	if len(os.Args) == 2 {
-		lox.runFile(os.Args[1])
-	} else {
+	lox.timeout = *timeout
+	lox.maxSteps = *maxSteps
+	lox.maxMemory = *maxMemory
+	switch {
+	case len(sources) > 0:
+		lox.args = flags.Args()
+	case flags.NArg() > 0:
+		lox.args = flags.Args()[1:]
+	}
+	lox.reset()
+
+	if *dumpTokensFlag || dumpAstFlag != "" {
+		var source string
+		switch {
+		case len(sources) > 0:
+			source = strings.Join(sources, "\n")
+		case flags.NArg() > 0:
+			source = readScript(flags.Arg(0))
+		default:
+			fmt.Fprintln(os.Stderr, "glox: nothing to dump without a script")
+			os.Exit(64)
+		}
+		dump(source, *dumpTokensFlag, string(dumpAstFlag))
+		return
+	}
+	if *watch {
+		if len(sources) > 0 || flags.NArg() == 0 || flags.Arg(0) == "-" {
+			fmt.Fprintln(os.Stderr, "glox: --watch needs a script file")
+			os.Exit(64)
+		}
+		lox.watch(flags.Arg(0))
+		return
+	}
+	if len(sources) > 0 {
+		lox.runScript(strings.Join(sources, "\n"), ".")
+		return
+	}
+	if flags.NArg() == 0 {
 		lox.runPrompt()
+		return
 	}
+	lox.runFile(flags.Arg(0))
+}
+
+// sourceList collects the values of a repeated -e flag.
+type sourceList []string
+
+func (s *sourceList) String() string {
+	return strings.Join(*s, "\n")
+}
+
+func (s *sourceList) Set(source string) error {
+	*s = append(*s, source)
+	return nil
+}
+
+// astFormatFlag is a flag that can be given alone, for S-expressions, or with
+// a format, as in --dump-ast=json.
+type astFormatFlag string
+
+func (f *astFormatFlag) String() string {
+	return string(*f)
+}
+
+func (f *astFormatFlag) Set(format string) error {
+	switch format {
+	case "true":
+		*f = astSExpr
+	case "false":
+		*f = ""
+	case astSExpr, astJSON:
+		*f = astFormatFlag(format)
+	default:
+		return fmt.Errorf("unknown format %q; want %s or %s", format, astSExpr, astJSON)
+	}
+	return nil
+}
+
+func (f *astFormatFlag) IsBoolFlag() bool {
+	return true
 }
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain lets the tests run the test binary as glox itself, so that they
+// can check what the commands print and the status they exit with.
+func TestMain(m *testing.M) {
+	if os.Getenv("GLOX_TEST_MAIN") == "1" {
+		os.Args = append([]string{"glox"}, os.Args[1:]...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// glox runs glox with args in dir, feeding it stdin, and returns what it
+// wrote to stdout and stderr and its exit status.
+func glox(t *testing.T, dir, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GLOX_TEST_MAIN=1")
+	cmd.Stdin = strings.NewReader(stdin)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatal(err)
+	}
+	return stdout.String(), stderr.String(), cmd.ProcessState.ExitCode()
+}
+
+// writeScripts creates a directory holding the given scripts.
+func writeScripts(t *testing.T, scripts map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, source := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCommands(t *testing.T) {
+	dir := writeScripts(t, map[string]string{
+		"ok.lox":      "print 1 + 2;\n",
+		"args.lox":    "print args;\n",
+		"fail.lox":    "print 1 + nil;\n",
+		"syntax.lox":  "print 1 +;\n",
+		"exit.lox":    "print 1; exit(3); print 2;\n",
+		"messy.lox":   "print 1+2;\n",
+		"resolve.lox": "{ var a = a; }\n",
+	})
+
+	cases := []struct {
+		name   string
+		args   []string
+		stdin  string
+		stdout string
+		code   int
+	}{
+		{"run", []string{"run", "ok.lox"}, "", "3\n", 0},
+		{"run by default", []string{"ok.lox"}, "", "3\n", 0},
+		{"script arguments", []string{"args.lox", "a", "b"}, "", "[a, b]\n", 0},
+		{"runtime error", []string{"fail.lox"}, "", "", 70},
+		{"static error", []string{"syntax.lox"}, "", "", 65},
+		{"resolve error", []string{"resolve.lox"}, "", "", 65},
+		{"missing script", []string{"missing.lox"}, "", "", 66},
+		{"exit", []string{"exit.lox"}, "", "1\n", 3},
+		{"bad flag", []string{"run", "--no-such-flag"}, "", "", 2},
+		{"check", []string{"check", "ok.lox", "messy.lox"}, "", "", 0},
+		{"check errors", []string{"check", "ok.lox", "syntax.lox"}, "", "", 65},
+		{"check nothing", []string{"check"}, "", "", 64},
+		{"fmt", []string{"fmt", "messy.lox"}, "", "print 1 + 2;\n", 0},
+		{"fmt stdin", []string{"fmt"}, "print 1+2;", "print 1 + 2;\n", 0},
+		{"fmt list", []string{"fmt", "-l", "ok.lox", "messy.lox"}, "", "messy.lox\n", 0},
+		{"fmt errors", []string{"fmt", "syntax.lox"}, "", "", 65},
+		{"bench static error", []string{"bench", "-n", "2", "syntax.lox"}, "", "", 65},
+		{"bench runtime error", []string{"bench", "-n", "2", "fail.lox"}, "", "", 70},
+		{"bench missing script", []string{"bench", "missing.lox"}, "", "", 66},
+		{"bench no runs", []string{"bench", "-n", "0", "ok.lox"}, "", "", 64},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.name, func(t *testing.T) {
+			stdout, stderr, code := glox(t, dir, cc.stdin, cc.args...)
+			if code != cc.code {
+				t.Errorf("want exit status %d, got %d; stderr:\n%s", cc.code, code, stderr)
+			}
+			if stdout != cc.stdout {
+				t.Errorf("want output %q, got %q", cc.stdout, stdout)
+			}
+		})
+	}
+}
+
+func TestBench(t *testing.T) {
+	dir := writeScripts(t, map[string]string{"ok.lox": "print 1 + 2;\n"})
+
+	stdout, stderr, code := glox(t, dir, "", "bench", "-n", "2", "ok.lox")
+	if code != 0 {
+		t.Fatalf("want exit status 0, got %d; stderr:\n%s", code, stderr)
+	}
+	// The script's own output is left out of the report.
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	want := []string{"runs:    2", "mean:    ", "stddev:  ", "allocs:  "}
+	if len(lines) != len(want) {
+		t.Fatalf("want %d lines, got\n%s", len(want), stdout)
+	}
+	for k := range want {
+		if !strings.HasPrefix(lines[k], want[k]) {
+			t.Errorf("want a line starting %q, got %q", want[k], lines[k])
+		}
+	}
+}
+
+func TestFmtWrite(t *testing.T) {
+	dir := writeScripts(t, map[string]string{"messy.lox": "print 1+2;\n"})
+
+	if _, stderr, code := glox(t, dir, "", "fmt", "-w", "messy.lox"); code != 0 {
+		t.Fatalf("want exit status 0, got %d; stderr:\n%s", code, stderr)
+	}
+	bytes, err := os.ReadFile(filepath.Join(dir, "messy.lox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "print 1 + 2;\n"; string(bytes) != want {
+		t.Errorf("want %q, got %q", want, bytes)
+	}
+}
+
+func TestSourceArguments(t *testing.T) {
+	dir := writeScripts(t, map[string]string{
+		"lib.lox": "var answer = 42;\n",
+	})
+
+	cases := []struct {
+		name   string
+		args   []string
+		stdin  string
+		stdout string
+		code   int
+	}{
+		{"source", []string{"-e", "print 1 + 2;"}, "", "3\n", 0},
+		{"lines", []string{"-e", "var a = 1;", "-e", "print a;"}, "", "1\n", 0},
+		{"arguments", []string{"-e", "print args;", "a", "b"}, "", "[a, b]\n", 0},
+		{"with run", []string{"run", "-e", "print 1;"}, "", "1\n", 0},
+		{"import", []string{"-e", `import "lib.lox"; print answer;`}, "", "42\n", 0},
+		{"static error", []string{"-e", "print 1 +;"}, "", "", 65},
+		{"runtime error", []string{"-e", "print 1 + nil;"}, "", "", 70},
+		{"exit", []string{"-e", "exit(4);"}, "", "", 4},
+		{"source reads stdin", []string{"-e", "print readLine();"}, "ignored\n", "ignored\n", 0},
+		{"stdin", []string{"-"}, "print 1 + 2;", "3\n", 0},
+		{"stdin arguments", []string{"-", "a"}, "print args;", "[a]\n", 0},
+		{"stdin import", []string{"run", "-"}, `import "lib.lox"; print answer;`, "42\n", 0},
+		{"stdin static error", []string{"-"}, "print 1 +;", "", 65},
+		{"stdin runtime error", []string{"-"}, "print 1 + nil;", "", 70},
+		{"empty stdin", []string{"-"}, "", "", 0},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.name, func(t *testing.T) {
+			stdout, stderr, code := glox(t, dir, cc.stdin, cc.args...)
+			if code != cc.code {
+				t.Errorf("want exit status %d, got %d; stderr:\n%s", cc.code, code, stderr)
+			}
+			if stdout != cc.stdout {
+				t.Errorf("want output %q, got %q", cc.stdout, stdout)
+			}
+		})
+	}
+}
+
+func TestDump(t *testing.T) {
+	dir := writeScripts(t, map[string]string{
+		"script.lox": "var a = \"x\ny\";\nprint -a;\n",
+		"fun.lox":    "fun f(a) { if (a) return 1; else { print a; } }\n",
+	})
+
+	tokens := `1:1	var "var"
+1:5	IDENTIFIER "a"
+1:7	= "="
+1:9	STRING "\"x\ny\"" "x\ny"
+2:3	; ";"
+3:1	print "print"
+3:7	- "-"
+3:8	IDENTIFIER "a"
+3:9	; ";"
+4:1	EOF
+`
+	sexpr := `(fun f (a)
+    (if a
+        (return 1)
+        (block
+            (print a))))
+`
+	json := `[
+  {
+    "expression": {
+      "type": "Literal",
+      "value": 1
+    },
+    "type": "Print"
+  }
+]
+`
+
+	cases := []struct {
+		name   string
+		args   []string
+		stdin  string
+		stdout string
+		code   int
+	}{
+		{"tokens", []string{"--dump-tokens", "script.lox"}, "", tokens, 0},
+		{"tokens from stdin", []string{"--dump-tokens", "-"}, "var a = \"x\ny\";\nprint -a;\n", tokens, 0},
+		{"ast", []string{"--dump-ast", "fun.lox"}, "", sexpr, 0},
+		{"ast as sexpr", []string{"--dump-ast=sexpr", "fun.lox"}, "", sexpr, 0},
+		{"ast as json", []string{"--dump-ast=json", "-e", "print 1;"}, "", json, 0},
+		{
+			"both",
+			[]string{"run", "--dump-tokens", "--dump-ast", "-e", "print 1;"},
+			"",
+			"1:1\tprint \"print\"\n1:7\tNUMBER \"1\" 1\n1:8\t; \";\"\n1:9\tEOF\n(print 1)\n",
+			0,
+		},
+		{"script not run", []string{"--dump-ast", "-e", "print 1 + nil;"}, "", "(print (+ 1 nil))\n", 0},
+		{"strings quoted", []string{"--dump-ast", "-e", `print "a"; print a; print "x y";`}, "", "(print \"a\")\n(print a)\n(print \"x y\")\n", 0},
+		{"scan error", []string{"--dump-tokens", "-e", `print "x`}, "", "1:1\tprint \"print\"\n1:9\tEOF\n", 65},
+		{"parse error", []string{"--dump-ast=json", "-e", "print 1 +;"}, "", "", 65},
+		{"unknown format", []string{"--dump-ast=xml", "-e", "print 1;"}, "", "", 2},
+		{"nothing to dump", []string{"--dump-tokens"}, "", "", 64},
+		{"missing script", []string{"--dump-ast", "missing.lox"}, "", "", 66},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.name, func(t *testing.T) {
+			stdout, stderr, code := glox(t, dir, cc.stdin, cc.args...)
+			if code != cc.code {
+				t.Errorf("want exit status %d, got %d; stderr:\n%s", cc.code, code, stderr)
+			}
+			if stdout != cc.stdout {
+				t.Errorf("want output\n%s\ngot\n%s", cc.stdout, stdout)
+			}
+		})
+	}
+}
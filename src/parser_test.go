@@ -1,6 +0,0 @@
-package main
-
-import "testing"
-
-func TestParser(t *testing.T) {
-}
@@ -0,0 +1,15 @@
+//go:build !linux && !darwin && !freebsd
+
+package main
+
+import "errors"
+
+// The line editor isn't available here, so the prompt reads plain lines.
+
+func isTerminal(fd uintptr) bool {
+	return false
+}
+
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported")
+}
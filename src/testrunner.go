@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/n4to4/glox/lox"
+)
+
+// Comments in test scripts that say what running them should do.
+const (
+	expectOutput = "// expect: "
+	expectError  = "// expect error: "
+)
+
+// runTest implements "glox test": it runs Lox scripts and checks what they
+// print against their "// expect: output" comments, in order. A script that
+// fails passes only if it has an "// expect error: message" comment naming
+// part of the error. Directories are searched for .lox files.
+func runTest(args []string) {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	timeout := flags.Duration("timeout", 10*time.Second, "fail a script that runs longer than this")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: glox test [flags] [file or directory...]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && (file == path || filepath.Ext(file) == ".lox") {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(66)
+		}
+	}
+
+	failed := 0
+	for _, file := range files {
+		start := time.Now()
+		problems := testScript(file, *timeout)
+		if len(problems) == 0 {
+			fmt.Printf("ok   %s (%v)\n", file, time.Since(start).Round(time.Millisecond))
+			continue
+		}
+
+		failed++
+		fmt.Printf("FAIL %s\n", file)
+		for _, problem := range problems {
+			fmt.Printf("    %s\n", strings.ReplaceAll(problem, "\n", "\n    "))
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("%d of %d scripts failed\n", failed, len(files))
+		os.Exit(1)
+	}
+}
+
+// testScript runs file and describes the ways it didn't do as expected.
+func testScript(file string, timeout time.Duration) []string {
+	source, err := os.ReadFile(file)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var wantOutput []string
+	var wantError string
+	for _, line := range strings.Split(string(source), "\n") {
+		if k := strings.Index(line, expectOutput); k >= 0 {
+			wantOutput = append(wantOutput, line[k+len(expectOutput):])
+		} else if k := strings.Index(line, expectError); k >= 0 {
+			wantError = line[k+len(expectError):]
+		}
+	}
+
+	stdout := &bytes.Buffer{}
+	interpreter := newInterpreter(nil)
+	interpreter.SetStdout(stdout)
+	interpreter.SetStdin(strings.NewReader(""))
+	interpreter.SetModuleLoader(lox.OSLoader{Dir: filepath.Dir(file)})
+
+	program, err := lox.Compile(string(source))
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err = interpreter.RunContext(ctx, program)
+		cancel()
+	}
+	var exit lox.ExitError
+	if errors.As(err, &exit) && exit.Code == 0 {
+		err = nil
+	}
+
+	var problems []string
+	switch {
+	case err != nil && wantError == "":
+		problems = append(problems, err.Error())
+	case err != nil && !strings.Contains(err.Error(), wantError):
+		problems = append(problems, fmt.Sprintf("want error %q, got %v", wantError, err))
+	case err == nil && wantError != "":
+		problems = append(problems, fmt.Sprintf("want error %q, got none", wantError))
+	}
+
+	// Scripts that don't say what they print are only checked for errors.
+	if len(wantOutput) > 0 {
+		output := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+		if problem := compareOutput(wantOutput, output); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	return problems
+}
+
+// compareOutput describes the first line where a script's output differs
+// from what was wanted, or returns "" if it doesn't.
+func compareOutput(want, got []string) string {
+	for k := 0; k < len(want) || k < len(got); k++ {
+		switch {
+		case k >= len(got):
+			return fmt.Sprintf("want output %q, got no more", want[k])
+		case k >= len(want):
+			return fmt.Sprintf("unexpected output %q", got[k])
+		case got[k] != want[k]:
+			return fmt.Sprintf("want output %q, got %q", want[k], got[k])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTestScript(t *testing.T) {
+	dir := writeScripts(t, map[string]string{
+		"pass.lox":         "print 1; // expect: 1\nprint \"a\"; // expect: a\n",
+		"unchecked.lox":    "print 1;\n",
+		"wrong.lox":        "print 1; // expect: 2\n",
+		"missing.lox":      "print 1; // expect: 1\n// expect: 2\n",
+		"extra.lox":        "print 1; // expect: 1\nprint 2;\n",
+		"error.lox":        "print 1 + nil; // expect error: operands must be\n",
+		"wrong-error.lox":  "print 1 + nil; // expect error: undefined\n",
+		"no-error.lox":     "print 1; // expect error: operands must be\n",
+		"unexpected.lox":   "print 1 + nil;\n",
+		"static-error.lox": "print 1 +; // expect error: Expect expression\n",
+		"exit.lox":         "print 1; // expect: 1\nexit(0);\nprint 2;\n",
+		"exit-status.lox":  "exit(2);\n",
+		"timeout.lox":      "while (true) {}\n",
+		"globals.lox":      "print args; // expect: []\nprint getenv(\"GLOX_NO_SUCH_VARIABLE\"); // expect: <nil>\n",
+	})
+
+	cases := []struct {
+		file string
+		want []string
+	}{
+		{"pass.lox", nil},
+		{"unchecked.lox", nil},
+		{"wrong.lox", []string{`want output "2", got "1"`}},
+		{"missing.lox", []string{`want output "2", got no more`}},
+		{"extra.lox", []string{`unexpected output "2"`}},
+		{"error.lox", nil},
+		{"wrong-error.lox", []string{`want error "undefined", got operands must be`}},
+		{"no-error.lox", []string{`want error "operands must be", got none`}},
+		{"unexpected.lox", []string{"operands must be"}},
+		{"static-error.lox", nil},
+		{"exit.lox", nil},
+		{"exit-status.lox", []string{"exit status 2"}},
+		{"timeout.lox", []string{"deadline exceeded"}},
+		{"globals.lox", nil},
+		{"no-such-file.lox", []string{"no such file"}},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.file, func(t *testing.T) {
+			got := testScript(filepath.Join(dir, cc.file), 100*time.Millisecond)
+			if len(got) != len(cc.want) {
+				t.Fatalf("want problems %q, got %q", cc.want, got)
+			}
+			for k := range got {
+				if !strings.Contains(got[k], cc.want[k]) {
+					t.Errorf("want problem containing %q, got %q", cc.want[k], got[k])
+				}
+			}
+		})
+	}
+}
+
+func TestCompareOutput(t *testing.T) {
+	cases := []struct {
+		name      string
+		want, got []string
+		problem   string
+	}{
+		{"same", []string{"a", "b"}, []string{"a", "b"}, ""},
+		{"different", []string{"a", "b"}, []string{"a", "c"}, `want output "b", got "c"`},
+		{"short", []string{"a", "b"}, []string{"a"}, `want output "b", got no more`},
+		{"long", []string{"a"}, []string{"a", "b"}, `unexpected output "b"`},
+	}
+
+	for _, cc := range cases {
+		t.Run(cc.name, func(t *testing.T) {
+			if got := compareOutput(cc.want, cc.got); got != cc.problem {
+				t.Errorf("want %q, got %q", cc.problem, got)
+			}
+		})
+	}
+}
+
+func TestRunTest(t *testing.T) {
+	dir := writeScripts(t, map[string]string{
+		"pass.lox": "print 1; // expect: 1\n",
+		"fail.lox": "print 1; // expect: 2\n",
+		"notes.md": "not a script\n",
+	})
+
+	stdout, _, code := glox(t, dir, "", "test", "pass.lox")
+	if code != 0 || !strings.HasPrefix(stdout, "ok   pass.lox (") {
+		t.Errorf("want pass.lox to pass, got status %d and\n%s", code, stdout)
+	}
+
+	stdout, _, code = glox(t, dir, "", "test")
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if code != 1 || len(lines) != 4 {
+		t.Fatalf("want two results and a summary with status 1, got status %d and\n%s", code, stdout)
+	}
+	want := []string{"FAIL fail.lox", `    want output "2", got "1"`, "1 of 2 scripts failed"}
+	if got := []string{lines[0], lines[1], lines[3]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want\n%s\ngot\n%s", strings.Join(want, "\n"), stdout)
+	}
+	if !strings.HasPrefix(lines[2], "ok   pass.lox (") {
+		t.Errorf("want pass.lox to pass, got %q", lines[2])
+	}
+}
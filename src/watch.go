@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/n4to4/glox/lox"
+)
+
+// watchInterval is how often --watch checks for changes.
+const watchInterval = 500 * time.Millisecond
+
+// watch runs a script with a fresh interpreter each time it, or a module it
+// imported, changes. Errors are reported and it carries on watching. A run
+// still going when a file changes is cancelled.
+func (l *Lox) watch(file string) {
+	l.watching = true
+	files := []string{file}
+
+	for {
+		// Clear the screen.
+		fmt.Fprint(l.stdout, "\x1b[H\x1b[2J")
+
+		l.reset()
+		l.hadError, l.hadRuntimeError = false, false
+
+		// Watch the files the last run used while this one runs. Their
+		// stamps from before the run stay the baseline afterwards, so that a
+		// save made during the run isn't missed.
+		stamps := fileStamps(files)
+		ctx, cancel := context.WithCancel(context.Background())
+		changed := make(chan bool, 1)
+		go func(files []string, stamps []fileStamp) {
+			if waitForChange(ctx, files, stamps) {
+				cancel()
+				changed <- true
+				return
+			}
+			changed <- false
+		}(files, stamps)
+
+		l.ctx = ctx
+		if bytes, err := os.ReadFile(file); err != nil {
+			fmt.Fprintln(l.stderr, err)
+		} else {
+			l.interpreter.SetModuleLoader(lox.OSLoader{Dir: filepath.Dir(file)})
+			l.run(string(bytes))
+		}
+		cancel()
+		if <-changed {
+			continue
+		}
+
+		// If the script didn't get as far as its imports, keep watching the
+		// modules it imported last time.
+		if !l.hadError {
+			imported := append([]string{file}, l.interpreter.Modules()...)
+			files, stamps = imported, keepStamps(files, stamps, imported)
+		}
+		fmt.Fprintf(l.stderr, "[watching %d file(s) for changes]\n", len(files))
+		waitForChange(context.Background(), files, stamps)
+	}
+}
+
+// keepStamps returns stamps for files, keeping the ones in stamps for the
+// files in previous and stamping the rest now.
+func keepStamps(previous []string, stamps []fileStamp, files []string) []fileStamp {
+	kept := make(map[string]fileStamp, len(previous))
+	for k, file := range previous {
+		kept[file] = stamps[k]
+	}
+
+	current := fileStamps(files)
+	for k, file := range files {
+		if stamp, ok := kept[file]; ok {
+			current[k] = stamp
+		}
+	}
+	return current
+}
+
+// waitForChange polls files until they no longer match stamps, reporting
+// true, or until ctx is done, reporting false.
+func waitForChange(ctx context.Context, files []string, stamps []fileStamp) bool {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+		if !stampsEqual(stamps, fileStamps(files)) {
+			return true
+		}
+	}
+}
+
+// fileStamp records enough about a file to notice when it changes. A
+// missing file has the zero stamp.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func fileStamps(files []string) []fileStamp {
+	stamps := make([]fileStamp, len(files))
+	for k, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			stamps[k] = fileStamp{info.ModTime(), info.Size()}
+		}
+	}
+	return stamps
+}
+
+func stampsEqual(a, b []fileStamp) bool {
+	for k := range a {
+		if !a[k].modTime.Equal(b[k].modTime) || a[k].size != b[k].size {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	dir := writeScripts(t, map[string]string{"loop.lox": "print \"started\";\nwhile (true) {}\n"})
+
+	cmd := exec.Command(os.Args[0], "--watch", "loop.lox")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GLOX_TEST_MAIN=1")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	output := make(chan string, 10)
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			output <- scanner.Text()
+		}
+	}()
+	diagnostics := make(chan string, 10)
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			diagnostics <- scanner.Text()
+		}
+	}()
+
+	// The first run never ends, so only a change made while it runs can
+	// lead to the script running again.
+	select {
+	case <-output:
+	case line := <-diagnostics:
+		t.Fatalf("want no errors, got %q", line)
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the script to start")
+	}
+	source := "print \"changed\";\nexit(0);\n"
+	if err := os.WriteFile(filepath.Join(dir, "loop.lox"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The run ends with exit(0), so glox should go back to watching without
+	// reporting it as an error.
+	changed, watching := false, false
+	for !changed || !watching {
+		select {
+		case line := <-output:
+			changed = changed || strings.HasSuffix(line, "changed")
+		case line := <-diagnostics:
+			if !strings.HasPrefix(line, "[watching") {
+				t.Fatalf("want no errors, got %q", line)
+			}
+			watching = true
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out; script ran again: %v, watching again: %v", changed, watching)
+		}
+	}
+}
+
+func TestKeepStamps(t *testing.T) {
+	dir := writeScripts(t, map[string]string{"main.lox": "print 1;\n", "lib.lox": "var a;\n"})
+	main, lib := filepath.Join(dir, "main.lox"), filepath.Join(dir, "lib.lox")
+
+	// main.lox is saved while it runs and imports lib.lox for the first time.
+	previous := []string{main}
+	stamps := fileStamps(previous)
+	if err := os.WriteFile(main, []byte("import \"lib.lox\";\nprint 1;\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	files := []string{main, lib}
+	kept := keepStamps(previous, stamps, files)
+	if kept[0] != stamps[0] {
+		t.Error("want the stamp from before the run kept for main.lox")
+	}
+	if now := fileStamps(files); kept[1] != now[1] {
+		t.Error("want lib.lox stamped now")
+	}
+	if stampsEqual(kept, fileStamps(files)) {
+		t.Error("want the save during the run to count as a change")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -19,18 +20,22 @@ func main() {
 		"Assign   : name Token, value Expr",
 		"Binary   : left Expr, operator Token, right Expr",
 		"Call     : callee Expr, paren Token, arguments []Expr",
+		"Get      : object Expr, name Token",
 		"Grouping : expression Expr",
 		"Literal  : value interface{}",
 		"Logical  : left Expr, operator Token, right Expr",
+		"Set      : object Expr, name Token, value Expr",
 		"Unary    : operator Token, right Expr",
 		"Variable : name Token",
 	})
 
 	defineAst(outputDir, "Stmt", []string{
-		"Block      : statements []Stmt",
+		"Block      : brace Token, statements []Stmt",
+		"Echo       : expression Expr",
 		"Expression : expression Expr",
 		"Function   : name Token, params []Token, body []Stmt",
 		"If         : condition Expr, thenBranch *Stmt, elseBranch *Stmt",
+		"Import     : keyword Token, path Token",
 		"Print      : expression Expr",
 		"Return     : keyword Token, value *Expr",
 		"Var        : name Token, initializer *Expr",
@@ -46,13 +51,18 @@ func defineAst(outputDir, baseName string, types []string) {
 	}
 	defer file.Close()
 
+	packageName, err := filepath.Abs(outputDir)
+	if err != nil {
+		log.Fatalf("cannot resolve directory %q, %v\n", outputDir, err)
+	}
+
 	w := bufio.NewWriter(file)
-	generateAst(w, baseName, types)
+	generateAst(w, filepath.Base(packageName), baseName, types)
 	w.Flush()
 }
 
-func generateAst(w io.StringWriter, baseName string, types []string) {
-	w.WriteString("package main\n")
+func generateAst(w io.StringWriter, packageName, baseName string, types []string) {
+	w.WriteString(fmt.Sprintf("package %s\n", packageName))
 	w.WriteString("\n")
 	w.WriteString(fmt.Sprintf("type %s interface {\n", baseName))
 	w.WriteString(fmt.Sprintf("\t%sAcceptor\n", baseName))